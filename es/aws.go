@@ -0,0 +1,131 @@
+package es
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awscredentials "github.com/aws/aws-sdk-go/aws/credentials"
+	awssigv4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/private/protocol/rest"
+	awssts "github.com/aws/aws-sdk-go/service/sts"
+)
+
+// awsSigningTransport signs requests with AWS Signature Version 4. The scheme
+// of the url is kept, so requests can be sent to a local stand-in for AWS like
+// LocalStack.
+type awsSigningTransport struct {
+	rt      http.RoundTripper
+	signer  *awssigv4.Signer
+	service string
+	region  string
+}
+
+func newAwsSigningTransport(rt http.RoundTripper, region string, conf *ProviderConf) (http.RoundTripper, error) {
+	session := awsSession(region, conf)
+	// Call Get() to ensure concurrency safe retrieval of credentials. Since the
+	// client is created in many go routines, this synchronizes it.
+	_, err := session.Config.Credentials.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	return &awsSigningTransport{
+		rt:      rt,
+		signer:  awssigv4.NewSigner(session.Config.Credentials),
+		service: conf.awsSig4Service,
+		region:  region,
+	}, nil
+}
+
+func (t *awsSigningTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.Contains(req.URL.RawPath, "%2C") {
+		req.URL.RawPath = rest.EscapePath(req.URL.RawPath, false)
+	}
+
+	var body io.ReadSeeker
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	// OpenSearch Serverless requires the payload hash to be sent as a header
+	if t.service == "aoss" {
+		hash := sha256.New()
+		if body != nil {
+			if _, err := io.Copy(hash, body); err != nil {
+				return nil, err
+			}
+			if _, err := body.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+		req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(hash.Sum(nil)))
+	}
+
+	if _, err := t.signer.Sign(req, body, t.service, t.region, time.Now()); err != nil {
+		return nil, fmt.Errorf("error signing request: %+v", err)
+	}
+	return t.rt.RoundTrip(req)
+}
+
+// webIdentityProvider assumes a role with the OIDC token in a file, e.g. the
+// token mounted into EKS pods for IAM roles for service accounts. The token is
+// read again whenever the credentials expire, since it is rotated.
+type webIdentityProvider struct {
+	awscredentials.Expiry
+
+	client      *awssts.STS
+	roleArn     string
+	sessionName string
+	tokenFile   string
+	duration    time.Duration
+	policy      string
+}
+
+func (p *webIdentityProvider) Retrieve() (awscredentials.Value, error) {
+	return p.RetrieveWithContext(aws.BackgroundContext())
+}
+
+func (p *webIdentityProvider) RetrieveWithContext(ctx awscredentials.Context) (awscredentials.Value, error) {
+	token, err := ioutil.ReadFile(p.tokenFile)
+	if err != nil {
+		return awscredentials.Value{}, fmt.Errorf("error reading `aws_assume_role_web_identity_token_file`: %+v", err)
+	}
+
+	input := &awssts.AssumeRoleWithWebIdentityInput{
+		RoleArn:          aws.String(p.roleArn),
+		RoleSessionName:  aws.String(p.sessionName),
+		WebIdentityToken: aws.String(strings.TrimSpace(string(token))),
+	}
+	if p.duration > 0 {
+		input.DurationSeconds = aws.Int64(int64(p.duration / time.Second))
+	}
+	if p.policy != "" {
+		input.Policy = aws.String(p.policy)
+	}
+
+	out, err := p.client.AssumeRoleWithWebIdentityWithContext(ctx, input)
+	if err != nil {
+		return awscredentials.Value{}, fmt.Errorf("error assuming role %s with web identity: %+v", p.roleArn, err)
+	}
+
+	p.SetExpiration(aws.TimeValue(out.Credentials.Expiration), time.Minute)
+	return awscredentials.Value{
+		AccessKeyID:     aws.StringValue(out.Credentials.AccessKeyId),
+		SecretAccessKey: aws.StringValue(out.Credentials.SecretAccessKey),
+		SessionToken:    aws.StringValue(out.Credentials.SessionToken),
+		ProviderName:    "WebIdentityCredentials",
+	}, nil
+}
@@ -0,0 +1,167 @@
+package es
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/olivere/elastic/uritemplates"
+)
+
+// capability describes the server versions that support a resource or
+// feature, per flavor. A nil constraint means the feature is not available on
+// that flavor at all.
+type capability struct {
+	elasticsearch version.Constraints
+	opensearch    version.Constraints
+}
+
+var capabilities = map[string]capability{
+	"elasticsearch_component_template": {
+		elasticsearch: mustVersionConstraints(">= 7.8"),
+		opensearch:    mustVersionConstraints(">= 1.0"),
+	},
+	"elasticsearch_composable_index_template": {
+		elasticsearch: mustVersionConstraints(">= 7.8"),
+		opensearch:    mustVersionConstraints(">= 1.0"),
+	},
+	"elasticsearch_data_stream": {
+		elasticsearch: mustVersionConstraints(">= 7.9"),
+		opensearch:    mustVersionConstraints(">= 1.0"),
+	},
+	"elasticsearch_kibana_alert": {
+		elasticsearch: mustVersionConstraints(">= 7.7"),
+	},
+	"elasticsearch_kibana_alert.notify_when": {
+		elasticsearch: mustVersionConstraints(">= 7.11"),
+	},
+	"elasticsearch_opensearch_audit_config": {
+		opensearch: mustVersionConstraints(">= 1.0"),
+	},
+	// OpenSearch plugin APIs are served under `_plugins`, the legacy
+	// `_opendistro` paths are deprecated and being removed
+	"opensearch_plugins_path": {
+		opensearch: mustVersionConstraints(">= 1.0"),
+	},
+}
+
+func mustVersionConstraints(c string) version.Constraints {
+	constraints, err := version.NewConstraint(c)
+	if err != nil {
+		panic(err)
+	}
+	return constraints
+}
+
+// serverVersion returns the parsed version of the cluster the provider is
+// connected to, detecting it first if needed.
+func serverVersion(conf *ProviderConf) (*version.Version, error) {
+	if _, err := getClient(conf); err != nil {
+		return nil, err
+	}
+
+	v, err := version.NewVersion(conf.esVersion)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse server version %q: %w", conf.esVersion, err)
+	}
+	return v, nil
+}
+
+// openSearchVersionConstraints are the released major versions of OpenSearch.
+var openSearchVersionConstraints = mustVersionConstraints(">= 1.0, < 4.0")
+
+// isOpenSearch reports whether the cluster should be treated as OpenSearch.
+// When the flavor could not be detected, e.g. when `elasticsearch_version` is
+// set, versions of the released OpenSearch majors are treated as OpenSearch,
+// unsupported Elasticsearch versions like 5.x are not.
+func isOpenSearch(conf *ProviderConf, v *version.Version) bool {
+	if conf.flavor == Unknown {
+		return openSearchVersionConstraints.Check(v)
+	}
+	return conf.flavor == OpenSearch
+}
+
+func (c capability) supports(conf *ProviderConf, v *version.Version) bool {
+	constraints := c.elasticsearch
+	if isOpenSearch(conf, v) {
+		constraints = c.opensearch
+	}
+	return constraints != nil && constraints.Check(v)
+}
+
+func (c capability) String() string {
+	var requirements []string
+	if c.elasticsearch != nil {
+		requirements = append(requirements, fmt.Sprintf("Elasticsearch %s", c.elasticsearch))
+	}
+	if c.opensearch != nil {
+		requirements = append(requirements, fmt.Sprintf("OpenSearch %s", c.opensearch))
+	}
+	return strings.Join(requirements, " or ")
+}
+
+// checkCapability returns an error if the named capability is not supported by
+// the cluster the provider is connected to.
+func checkCapability(meta interface{}, name string) error {
+	c, ok := capabilities[name]
+	if !ok {
+		return fmt.Errorf("unknown capability %s", name)
+	}
+
+	conf := meta.(*ProviderConf)
+	v, err := serverVersion(conf)
+	if err != nil {
+		return err
+	}
+
+	if !c.supports(conf, v) {
+		return fmt.Errorf("%s requires %s, cluster is %s", name, c, v)
+	}
+	return nil
+}
+
+// hasCapability is like checkCapability for optional features, it returns
+// false when the capability is not supported or the version can't be
+// determined.
+func hasCapability(meta interface{}, name string) bool {
+	return checkCapability(meta, name) == nil
+}
+
+// pluginPath expands the path of an OpenSearch/Open Distro plugin API, e.g.
+// `_ism/policies/{policy_id}`, prefixing it with `_plugins` or `_opendistro`
+// depending on the cluster the provider is connected to.
+func pluginPath(meta interface{}, path string, values map[string]string) (string, error) {
+	conf := meta.(*ProviderConf)
+	if _, err := serverVersion(conf); err != nil {
+		return "", err
+	}
+
+	prefix := "_opendistro"
+	if hasCapability(conf, "opensearch_plugins_path") {
+		prefix = "_plugins"
+	}
+
+	return uritemplates.Expand("/"+prefix+"/"+path, values)
+}
+
+// capabilityCustomizeDiff validates the named capability while planning, so
+// unsupported resources are reported before anything is applied.
+func capabilityCustomizeDiff(name string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		return checkCapability(meta, name)
+	}
+}
+
+// serverlessCustomizeDiff fails the plan of resources using APIs which Amazon
+// OpenSearch Serverless collections don't provide, e.g. cluster settings,
+// snapshot repositories and ISM.
+func serverlessCustomizeDiff(name string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		if meta.(*ProviderConf).serverless {
+			return fmt.Errorf("%s is not supported on Amazon OpenSearch Serverless", name)
+		}
+		return nil
+	}
+}
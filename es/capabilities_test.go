@@ -0,0 +1,94 @@
+package es
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+func TestCheckCapability(t *testing.T) {
+	cases := []struct {
+		version  string
+		flavor   ServerFlavor
+		name     string
+		expected string
+	}{
+		{"7.9.0", Elasticsearch, "elasticsearch_data_stream", ""},
+		{"10.0.0", Elasticsearch, "elasticsearch_data_stream", ""},
+		{"7.4.2", Elasticsearch, "elasticsearch_data_stream", "elasticsearch_data_stream requires Elasticsearch >= 7.9 or OpenSearch >= 1.0, cluster is 7.4.2"},
+		{"1.3.0", OpenSearch, "elasticsearch_data_stream", ""},
+		{"2.4.0", Unknown, "elasticsearch_data_stream", ""},
+		{"2.4.0", Unknown, "elasticsearch_kibana_alert", "elasticsearch_kibana_alert requires Elasticsearch >= 7.7, cluster is 2.4.0"},
+		{"7.10.2", ElasticsearchOpenSource, "elasticsearch_opensearch_audit_config", "elasticsearch_opensearch_audit_config requires OpenSearch >= 1.0, cluster is 7.10.2"},
+		{"5.6.0", Unknown, "elasticsearch_data_stream", "elasticsearch_data_stream requires Elasticsearch >= 7.9 or OpenSearch >= 1.0, cluster is 5.6.0"},
+	}
+
+	for _, tc := range cases {
+		conf := &ProviderConf{
+			esVersion: tc.version,
+			flavor:    tc.flavor,
+			client:    &elastic7.Client{},
+		}
+
+		err := checkCapability(conf, tc.name)
+		if tc.expected == "" && err != nil {
+			t.Errorf("%s on %s: unexpected error: %s", tc.name, tc.version, err)
+		}
+		if tc.expected != "" && (err == nil || err.Error() != tc.expected) {
+			t.Errorf("%s on %s: expected error %q, got %v", tc.name, tc.version, tc.expected, err)
+		}
+	}
+
+	// an explicit Elasticsearch 5 version isn't mistaken for OpenSearch
+	conf := &ProviderConf{esVersion: "5.6.0", rawUrl: "http://127.0.0.1:9200", urls: []string{"http://127.0.0.1:9200"}}
+	conf.parsedUrl, _ = url.Parse(conf.rawUrl)
+	if _, err := getClient(conf); err == nil || !strings.Contains(err.Error(), "older than 6.0.0 and is not supported") {
+		t.Errorf("expected Elasticsearch 5.6.0 to be rejected, got %v", err)
+	}
+}
+
+func TestPluginPath(t *testing.T) {
+	cases := []struct {
+		version  string
+		flavor   ServerFlavor
+		expected string
+	}{
+		{"7.10.2", ElasticsearchOpenSource, "/_opendistro/_ism/policies/my-policy"},
+		{"1.3.0", OpenSearch, "/_plugins/_ism/policies/my-policy"},
+		{"2.4.0", OpenSearch, "/_plugins/_ism/policies/my-policy"},
+		{"2.4.0", Unknown, "/_plugins/_ism/policies/my-policy"},
+		{"5.6.0", Unknown, "/_opendistro/_ism/policies/my-policy"},
+	}
+
+	for _, tc := range cases {
+		conf := &ProviderConf{
+			esVersion: tc.version,
+			flavor:    tc.flavor,
+			client:    &elastic7.Client{},
+		}
+
+		path, err := pluginPath(conf, "_ism/policies/{policy_id}", map[string]string{"policy_id": "my-policy"})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if path != tc.expected {
+			t.Errorf("%s (%v): expected %s, got %s", tc.version, tc.flavor, tc.expected, path)
+		}
+	}
+}
+
+func TestServerlessCustomizeDiff(t *testing.T) {
+	diff := serverlessCustomizeDiff("elasticsearch_cluster_settings")
+
+	if err := diff(context.TODO(), nil, &ProviderConf{}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	err := diff(context.TODO(), nil, &ProviderConf{serverless: true})
+	expected := "elasticsearch_cluster_settings is not supported on Amazon OpenSearch Serverless"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
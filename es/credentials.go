@@ -0,0 +1,162 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// credentialsCommandTimeout bounds how long `credentials_command` may take.
+const credentialsCommandTimeout = time.Minute
+
+// credentialsExpiryWindow is how long before their expiration credentials
+// printed by `credentials_command` are refreshed.
+const credentialsExpiryWindow = time.Minute
+
+// externalCredentials are the credentials read from `password_file` or
+// `token_file`, or printed as JSON by `credentials_command`.
+type externalCredentials struct {
+	Username   string    `json:"username"`
+	Password   string    `json:"password"`
+	Token      string    `json:"token"`
+	TokenName  string    `json:"token_name"`
+	Expiration time.Time `json:"expiration"`
+}
+
+// credentialsSource loads credentials from files or a command and caches them.
+// Files are read again when they change, the command is run again when the
+// credentials it printed expire. Both are reloaded when the cluster rejects
+// the cached credentials, e.g. after a token was rotated.
+type credentialsSource struct {
+	passwordFile string
+	tokenFile    string
+	command      []string
+	// username and tokenName are used when the credentials don't set them
+	username  string
+	tokenName string
+
+	mu      sync.Mutex
+	cached  *externalCredentials
+	modTime time.Time
+}
+
+// authorization returns the value of the Authorization header for the
+// credentials, reloading them if refresh is set or they are stale.
+func (s *credentialsSource) authorization(refresh bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if refresh || s.stale() {
+		creds, err := s.load()
+		if err != nil {
+			return "", err
+		}
+		s.cached = creds
+	}
+
+	if s.cached.Token != "" {
+		return fmt.Sprintf("%s %s", s.cached.TokenName, s.cached.Token), nil
+	}
+	auth := s.cached.Username + ":" + s.cached.Password
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(auth)), nil
+}
+
+func (s *credentialsSource) stale() bool {
+	if s.cached == nil {
+		return true
+	}
+	if !s.cached.Expiration.IsZero() && time.Now().Add(credentialsExpiryWindow).After(s.cached.Expiration) {
+		return true
+	}
+	if path := s.file(); path != "" {
+		info, err := os.Stat(path)
+		return err != nil || !info.ModTime().Equal(s.modTime)
+	}
+	return false
+}
+
+func (s *credentialsSource) file() string {
+	if s.passwordFile != "" {
+		return s.passwordFile
+	}
+	return s.tokenFile
+}
+
+func (s *credentialsSource) load() (*externalCredentials, error) {
+	var creds *externalCredentials
+	var err error
+	if len(s.command) > 0 {
+		creds, err = s.runCommand()
+	} else {
+		creds, err = s.readFile()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if creds.Username == "" {
+		creds.Username = s.username
+	}
+	if creds.TokenName == "" {
+		creds.TokenName = s.tokenName
+	}
+	return creds, nil
+}
+
+func (s *credentialsSource) readFile() (*externalCredentials, error) {
+	arg := "`password_file`"
+	if s.tokenFile != "" {
+		arg = "`token_file`"
+	}
+
+	path := s.file()
+	contents, isPath, err := readPathOrContent(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s %s: %+v", arg, path, err)
+	}
+	if !isPath {
+		return nil, fmt.Errorf("error reading %s %s: file does not exist", arg, path)
+	}
+	secret := strings.TrimSpace(contents)
+	if secret == "" {
+		return nil, fmt.Errorf("%s %s is empty", arg, path)
+	}
+	if info, err := os.Stat(path); err == nil {
+		s.modTime = info.ModTime()
+	}
+
+	if s.tokenFile != "" {
+		return &externalCredentials{Token: secret}, nil
+	}
+	return &externalCredentials{Password: secret}, nil
+}
+
+func (s *credentialsSource) runCommand() (*externalCredentials, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), credentialsCommandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.command[0], s.command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error running `credentials_command`: %+v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var creds externalCredentials
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return nil, fmt.Errorf("error parsing the output of `credentials_command` as JSON: %+v", err)
+	}
+	if creds.Token == "" && creds.Password == "" {
+		return nil, errors.New("`credentials_command` must print a `token` or a `password`")
+	}
+	return &creds, nil
+}
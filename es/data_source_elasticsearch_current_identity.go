@@ -0,0 +1,168 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+// identity is the principal the provider is authenticated as.
+type identity struct {
+	Username           string
+	Roles              []string
+	Realm              string
+	AuthenticationType string
+}
+
+func dataSourceElasticsearchCurrentIdentity() *schema.Resource {
+	return &schema.Resource{
+		Description: "`elasticsearch_current_identity` can be used to retrieve the user the provider is authenticated as, e.g. to assert a module runs as the expected principal.",
+		ReadContext: dataSourceElasticsearchCurrentIdentityRead,
+
+		Schema: map[string]*schema.Schema{
+			"username": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "the name of the authenticated user",
+			},
+			"roles": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "the roles of the authenticated user",
+			},
+			"realm": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "the name of the realm that authenticated the user, empty on OpenSearch",
+			},
+			"authentication_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "how the user was authenticated, e.g. `realm`, `api_key` or `token`, empty on OpenSearch",
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchCurrentIdentityRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	id, err := currentIdentity(ctx, m.(*ProviderConf))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id.Username)
+	ds := &resourceDataSetter{d: d}
+	ds.set("username", id.Username)
+	ds.set("roles", id.Roles)
+	ds.set("realm", id.Realm)
+	ds.set("authentication_type", id.AuthenticationType)
+
+	return diag.FromErr(ds.err)
+}
+
+// currentIdentity returns the user the provider is authenticated as, using the
+// Elasticsearch `_security/_authenticate` or the OpenSearch security plugin
+// `authinfo` API.
+func currentIdentity(ctx context.Context, conf *ProviderConf) (*identity, error) {
+	if conf.serverless {
+		return nil, errors.New("the identity can't be retrieved from Amazon OpenSearch Serverless")
+	}
+
+	esClient, err := getClient(conf)
+	if err != nil {
+		return nil, identityError(conf, err)
+	}
+
+	v, err := serverVersion(conf)
+	if err != nil {
+		return nil, err
+	}
+	securityPlugin := isOpenSearch(conf, v) || conf.flavor == ElasticsearchOpenSource
+
+	path := "/_security/_authenticate"
+	if securityPlugin {
+		path, err = pluginPath(conf, "_security/authinfo", nil)
+		if err != nil {
+			return nil, err
+		}
+	} else if _, ok := esClient.(*elastic6.Client); ok {
+		path = "/_xpack/security/_authenticate"
+	}
+
+	var body json.RawMessage
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   path,
+		})
+		if res != nil {
+			body = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
+			Method: "GET",
+			Path:   path,
+		})
+		if res != nil {
+			body = res.Body
+		}
+	default:
+		return nil, errors.New("this version of Elasticsearch is not supported")
+	}
+	if err != nil {
+		return nil, identityError(conf, err)
+	}
+
+	if securityPlugin {
+		var info struct {
+			UserName string   `json:"user_name"`
+			Roles    []string `json:"roles"`
+		}
+		if err := json.Unmarshal(body, &info); err != nil {
+			return nil, fmt.Errorf("error unmarshalling authinfo: %+v: %s", err, body)
+		}
+		return &identity{Username: info.UserName, Roles: info.Roles}, nil
+	}
+
+	var info struct {
+		Username            string   `json:"username"`
+		Roles               []string `json:"roles"`
+		AuthenticationType  string   `json:"authentication_type"`
+		AuthenticationRealm struct {
+			Name string `json:"name"`
+		} `json:"authentication_realm"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("error unmarshalling authenticate response: %+v: %s", err, body)
+	}
+	return &identity{
+		Username:           info.Username,
+		Roles:              info.Roles,
+		Realm:              info.AuthenticationRealm.Name,
+		AuthenticationType: info.AuthenticationType,
+	}, nil
+}
+
+// identityError replaces authentication and authorization errors with
+// messages pointing at the likely misconfiguration.
+func identityError(conf *ProviderConf, err error) error {
+	if elastic7.IsUnauthorized(err) || elastic6.IsStatusCode(err, http.StatusUnauthorized) {
+		return fmt.Errorf("HTTP 401 Unauthorized: the credentials for %s were rejected, please check `username`/`password`, `token` or the AWS credentials", conf.rawUrl)
+	}
+	if elastic7.IsForbidden(err) || elastic6.IsForbidden(err) {
+		return fmt.Errorf("HTTP 403 Forbidden: the user is not allowed to read its identity on %s, please check its roles", conf.rawUrl)
+	}
+	return fmt.Errorf("error retrieving the current identity: %+v", err)
+}
@@ -1,9 +1,10 @@
 package es
 
 import (
+	"context"
 	"errors"
-	"reflect"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -13,7 +14,7 @@ import (
 func dataSourceElasticsearchHost() *schema.Resource {
 	return &schema.Resource{
 		Description: "`elasticsearch_host` can be used to retrieve the host URL for the provider's current elasticsearch cluster.",
-		Read:        dataSourceElasticsearchHostRead,
+		ReadContext: dataSourceElasticsearchHostRead,
 
 		Schema: map[string]*schema.Schema{
 			"active": {
@@ -24,40 +25,48 @@ func dataSourceElasticsearchHost() *schema.Resource {
 			"url": {
 				Type:        schema.TypeString,
 				Computed:    true,
-				Description: "the url of the active elasticsearch cluster",
+				Description: "the url of the elasticsearch cluster that answered the last request",
 			},
 		},
 	}
 }
 
-func dataSourceElasticsearchHostRead(d *schema.ResourceData, m interface{}) error {
-
-	// The upstream elastic client does not export the property for the urls
-	// it's using. Presumably the URLS would be available where the client is
-	// intantiated, but in terraform, that's not always practicable.
-	var err error
-	esClient, err := getClient(m.(*ProviderConf))
+func dataSourceElasticsearchHostRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*ProviderConf)
+	esClient, err := getClient(conf)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	var url string
+	// Make a request so the url that's currently answering is known, with
+	// multiple urls this can differ from the first configured one.
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		urls := reflect.ValueOf(client).Elem().FieldByName("urls")
-		if urls.Len() > 0 {
-			url = urls.Index(0).String()
-		}
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
+			Method: "HEAD",
+			Path:   "/",
+		})
 	case *elastic6.Client:
-		urls := reflect.ValueOf(client).Elem().FieldByName("urls")
-		if urls.Len() > 0 {
-			url = urls.Index(0).String()
-		}
+		_, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
+			Method: "HEAD",
+			Path:   "/",
+		})
 	default:
-		return errors.New("this version of Elasticsearch is not supported")
+		return diag.FromErr(errors.New("this version of Elasticsearch is not supported"))
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var url string
+	if conf.endpoints != nil {
+		url = conf.endpoints.Active()
+	}
+	if url == "" && len(conf.urls) > 0 {
+		url = conf.urls[0]
 	}
 	d.SetId(url)
 	err = d.Set("url", url)
 
-	return err
+	return diag.FromErr(err)
 }
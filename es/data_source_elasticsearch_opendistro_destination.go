@@ -7,9 +7,9 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
-	"github.com/olivere/elastic/uritemplates"
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic6 "gopkg.in/olivere/elastic.v6"
 )
@@ -32,7 +32,7 @@ var datasourceOpenDistroDestinationSchema = map[string]*schema.Schema{
 func dataSourceOpenSearchDestination() *schema.Resource {
 	return &schema.Resource{
 		Description: "`elasticsearch_opensearch_destination` can be used to retrieve the destination object by name.",
-		Read:        dataSourceElasticsearchOpenDistroDestinationRead,
+		ReadContext: dataSourceElasticsearchOpenDistroDestinationRead,
 		Schema:      datasourceOpenDistroDestinationSchema,
 	}
 }
@@ -40,13 +40,13 @@ func dataSourceOpenSearchDestination() *schema.Resource {
 func dataSourceElasticsearchOpenDistroDestination() *schema.Resource {
 	return &schema.Resource{
 		Description:        "`elasticsearch_opendistro_destination` can be used to retrieve the destination object by name.",
-		Read:               dataSourceElasticsearchOpenDistroDestinationRead,
+		ReadContext:        dataSourceElasticsearchOpenDistroDestinationRead,
 		Schema:             datasourceOpenDistroDestinationSchema,
 		DeprecationMessage: "elasticsearch_opendistro_destination is deprecated, please use elasticsearch_opensearch_destination data source instead.",
 	}
 }
 
-func dataSourceElasticsearchOpenDistroDestinationRead(d *schema.ResourceData, m interface{}) error {
+func dataSourceElasticsearchOpenDistroDestinationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	destinationName := d.Get("name").(string)
 
 	var id string
@@ -54,7 +54,7 @@ func dataSourceElasticsearchOpenDistroDestinationRead(d *schema.ResourceData, m
 	var err error
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
@@ -63,18 +63,18 @@ func dataSourceElasticsearchOpenDistroDestinationRead(d *schema.ResourceData, m
 		// the index has become a "system index", so it cannot be searched:
 		// https://opendistro.github.io/for-elasticsearch-docs/docs/alerting/settings/#alerting-indices
 		// instead we paginate through all destinations to find the first name match :|
-		id, destination, err = destinationElasticsearch7GetAll(client, destinationName)
+		id, destination, err = destinationElasticsearch7GetAll(ctx, client, destinationName, m)
 		if err != nil {
-			id, destination, err = destinationElasticsearch7Search(client, DESTINATION_INDEX, destinationName)
+			id, destination, err = destinationElasticsearch7Search(ctx, client, DESTINATION_INDEX, destinationName)
 		}
 	case *elastic6.Client:
-		id, destination, err = destinationElasticsearch6Search(client, DESTINATION_INDEX, destinationName)
+		id, destination, err = destinationElasticsearch6Search(ctx, client, DESTINATION_INDEX, destinationName)
 	default:
 		err = errors.New("destination resource not implemented prior to Elastic v6")
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	} else if id == "" {
 		// short circuit
 		return nil
@@ -94,15 +94,15 @@ func dataSourceElasticsearchOpenDistroDestinationRead(d *schema.ResourceData, m
 		}
 	}
 	err = d.Set("body", simplifiedBody)
-	return err
+	return diag.FromErr(err)
 }
 
-func destinationElasticsearch7Search(client *elastic7.Client, index string, name string) (string, map[string]interface{}, error) {
+func destinationElasticsearch7Search(ctx context.Context, client *elastic7.Client, index string, name string) (string, map[string]interface{}, error) {
 	termQuery := elastic7.NewTermQuery(DESTINATION_NAME_FIELD, name)
 	result, err := client.Search().
 		Index(index).
 		Query(termQuery).
-		Do(context.TODO())
+		Do(ctx)
 
 	destination := make(map[string]interface{})
 	if err != nil {
@@ -121,12 +121,12 @@ func destinationElasticsearch7Search(client *elastic7.Client, index string, name
 	}
 }
 
-func destinationElasticsearch6Search(client *elastic6.Client, index string, name string) (string, map[string]interface{}, error) {
+func destinationElasticsearch6Search(ctx context.Context, client *elastic6.Client, index string, name string) (string, map[string]interface{}, error) {
 	termQuery := elastic6.NewTermQuery(DESTINATION_NAME_FIELD, name)
 	result, err := client.Search().
 		Index(index).
 		Query(termQuery).
-		Do(context.TODO())
+		Do(ctx)
 
 	destination := make(map[string]interface{})
 	if err != nil {
@@ -145,12 +145,12 @@ func destinationElasticsearch6Search(client *elastic6.Client, index string, name
 	}
 }
 
-func destinationElasticsearch7GetAll(client *elastic7.Client, name string) (string, map[string]interface{}, error) {
+func destinationElasticsearch7GetAll(ctx context.Context, client *elastic7.Client, name string, m interface{}) (string, map[string]interface{}, error) {
 	offset := 0
 	pageSize := 1000
 	destination := make(map[string]interface{})
 	for {
-		path, err := uritemplates.Expand("/_opendistro/_alerting/destinations?startIndex={startIndex}&size={size}", map[string]string{
+		path, err := pluginPath(m, "_alerting/destinations?startIndex={startIndex}&size={size}", map[string]string{
 			"startIndex": fmt.Sprint(offset),
 			"size":       fmt.Sprint(pageSize),
 		})
@@ -158,7 +158,7 @@ func destinationElasticsearch7GetAll(client *elastic7.Client, name string) (stri
 			return "", destination, fmt.Errorf("error building URL path for destination: %+v", err)
 		}
 
-		httpResponse, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		httpResponse, err := client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "GET",
 			Path:   path,
 		})
@@ -2,6 +2,7 @@ package es
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -122,6 +123,76 @@ func suppressEquivalentJson(k, old, new string, d *schema.ResourceData) bool {
 	return reflect.DeepEqual(oldObj, newObj)
 }
 
+func diffSuppressIndexAliases(k, old, new string, d *schema.ResourceData) bool {
+	oo := map[string]interface{}{}
+	no := map[string]interface{}{}
+	if old != "" {
+		if err := json.Unmarshal([]byte(old), &oo); err != nil {
+			return false
+		}
+	}
+	if new != "" {
+		if err := json.Unmarshal([]byte(new), &no); err != nil {
+			return false
+		}
+	}
+
+	normalizeIndexAliases(oo)
+	normalizeIndexAliases(no)
+
+	return reflect.DeepEqual(oo, no)
+}
+
+func diffSuppressIndexSettingsRaw(k, old, new string, d *schema.ResourceData) bool {
+	oo, err := flatRawSettings(old)
+	if err != nil {
+		return false
+	}
+	no, err := flatRawSettings(new)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(normalizedIndexSettings(oo), normalizedIndexSettings(no))
+}
+
+func diffSuppressIndexMappings(k, old, new string, d *schema.ResourceData) bool {
+	oo, err := unmarshalJSONObject(old)
+	if err != nil {
+		return false
+	}
+	no, err := unmarshalJSONObject(new)
+	if err != nil {
+		return false
+	}
+
+	om, nm := comparableMappings(oo, no)
+	return reflect.DeepEqual(om, nm)
+}
+
+// diffSuppressIndexSettingsJSON compares JSON objects of index settings, such
+// as analyzers, flattened and with values as strings as returned by the get
+// index API.
+func diffSuppressIndexSettingsJSON(k, old, new string, d *schema.ResourceData) bool {
+	oo, err := unmarshalJSONObject(old)
+	if err != nil {
+		return false
+	}
+	no, err := unmarshalJSONObject(new)
+	if err != nil {
+		return false
+	}
+
+	of, nf := flattenMap(oo), flattenMap(no)
+	for _, f := range []map[string]interface{}{of, nf} {
+		for k, v := range f {
+			f[k] = fmt.Sprintf("%v", v)
+		}
+	}
+
+	return reflect.DeepEqual(of, nf)
+}
+
 func diffSuppressIndexLifecyclePolicy(k, old, new string, d *schema.ResourceData) bool {
 	var oo, no interface{}
 	if err := json.Unmarshal([]byte(old), &oo); err != nil {
@@ -1,9 +1,228 @@
 package es
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// newTransport returns a transport with the defaults of http.DefaultTransport
+// which sends requests through the proxy of the provider.
+func newTransport(conf *ProviderConf, tlsConfig *tls.Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = conf.proxy
+	transport.TLSClientConfig = tlsConfig
+	return transport
+}
+
+// newHttpClient builds the HTTP client for conf. The settings are layered on
+// a single transport so they can be combined, e.g. client certificates with an
+// API key or AWS request signing:
+//
+//	headers and host override -> authentication -> concurrency limit ->
+//	request log -> TLS and proxy
+//
+// Requests are signed with SigV4 when awsRegion is set, otherwise they are
+// authenticated with the token or basic auth credentials, if any.
+func newHttpClient(conf *ProviderConf, headers map[string]string, awsRegion string) (*http.Client, error) {
+	tlsConfig, err := newTLSConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var rt http.RoundTripper = warningTransport{rt: newTransport(conf, tlsConfig)}
+	if conf.requestLog != nil {
+		rt = requestLogTransport{rt: rt, logger: conf.requestLog}
+	}
+	if conf.requestLimiter != nil {
+		rt = limitTransport{rt: rt, limiter: conf.requestLimiter}
+	}
+	if awsRegion != "" {
+		log.Printf("[INFO] Using AWS: %+v", awsRegion)
+		rt, err = newAwsSigningTransport(rt, awsRegion, conf)
+		if err != nil {
+			return nil, err
+		}
+	} else if conf.credentials != nil {
+		rt = credentialsTransport{rt: rt, credentials: conf.credentials}
+	} else if conf.token != "" {
+		rt = authTransport{rt: rt, authorization: fmt.Sprintf("%s %s", conf.tokenName, conf.token)}
+	} else if username, password := conf.basicAuth(); username != "" {
+		req := &http.Request{Header: make(http.Header)}
+		req.SetBasicAuth(username, password)
+		rt = authTransport{rt: rt, authorization: req.Header.Get("Authorization")}
+	}
+
+	h := WithHeader(rt)
+	h.hostOverride = conf.hostOverride
+	for k, v := range headers {
+		h.Set(k, v)
+	}
+
+	return &http.Client{Transport: h, Timeout: conf.requestTimeout}, nil
+}
+
+// basicAuth returns the basic auth credentials of conf, `username` and
+// `password` take precedence over credentials in the url.
+func (conf *ProviderConf) basicAuth() (string, string) {
+	if conf.username != "" && conf.password != "" {
+		return conf.username, conf.password
+	}
+	if conf.parsedUrl != nil && conf.parsedUrl.User.Username() != "" {
+		p, _ := conf.parsedUrl.User.Password()
+		return conf.parsedUrl.User.Username(), p
+	}
+	return "", ""
+}
+
+// awsSigningRegion returns the region requests should be signed for, or an
+// empty string if they shouldn't be signed.
+func awsSigningRegion(conf *ProviderConf) string {
+	if !conf.signAWSRequests {
+		return ""
+	}
+	if m := awsUrlRegexp.FindStringSubmatch(conf.parsedUrl.Hostname()); m != nil {
+		return m[1]
+	}
+	if m := aossUrlRegexp.FindStringSubmatch(conf.parsedUrl.Hostname()); m != nil {
+		return m[1]
+	}
+	return conf.awsRegion
+}
+
+// authTransport sets the Authorization header of requests, unless one was set
+// explicitly, e.g. through the provider headers.
+type authTransport struct {
+	rt            http.RoundTripper
+	authorization string
+}
+
+func (t authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") == "" {
+		req.Header.Set("Authorization", t.authorization)
+	}
+	return t.rt.RoundTrip(req)
+}
+
+// credentialsTransport sets the Authorization header of requests from
+// credentials loaded from files or a command. When they are rejected, they are
+// reloaded and the request is retried once, in case they were rotated.
+type credentialsTransport struct {
+	rt          http.RoundTripper
+	credentials *credentialsSource
+}
+
+func (t credentialsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") != "" {
+		return t.rt.RoundTrip(req)
+	}
+
+	authorization, err := t.credentials.authorization(false)
+	if err != nil {
+		return nil, err
+	}
+
+	// buffer the body so the request can be retried
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	retry := req.Clone(req.Context())
+	req.Header.Set("Authorization", authorization)
+	res, err := t.rt.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+
+	refreshed, rerr := t.credentials.authorization(true)
+	if rerr != nil {
+		log.Printf("[WARN] Unable to reload credentials: %+v", rerr)
+		return res, nil
+	}
+	if refreshed == authorization {
+		return res, nil
+	}
+
+	log.Printf("[INFO] Credentials were rejected, retrying with reloaded credentials")
+	res.Body.Close()
+	if body != nil {
+		retry.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	retry.Header.Set("Authorization", refreshed)
+	return t.rt.RoundTrip(retry)
+}
+
+// newTLSConfig builds the TLS configuration for the client certificate, CA
+// bundle and server name settings, returning an error if any of them can't be
+// loaded.
+func newTLSConfig(conf *ProviderConf) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if conf.certPemPath != "" || conf.keyPemPath != "" {
+		if conf.certPemPath == "" || conf.keyPemPath == "" {
+			return nil, errors.New("`client_cert_path` and `client_key_path` must be set together")
+		}
+		certPem, _, err := readPathOrContent(conf.certPemPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading `client_cert_path` %s: %+v", conf.certPemPath, err)
+		}
+		keyPem, _, err := readPathOrContent(conf.keyPemPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading `client_key_path` %s: %+v", conf.keyPemPath, err)
+		}
+		cert, err := tls.X509KeyPair([]byte(certPem), []byte(keyPem))
+		if err != nil {
+			return nil, fmt.Errorf("error loading the client certificate from `client_cert_path` and `client_key_path`, please check they are a matching PEM encoded certificate and key: %+v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	// If a cacertFile has been specified, use that for cert validation
+	if conf.cacertFile != "" {
+		caCert, isPath, err := readPathOrContent(conf.cacertFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading `cacert_file` %s: %+v", conf.cacertFile, err)
+		}
+
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM([]byte(caCert)) {
+			if isPath {
+				return nil, fmt.Errorf("`cacert_file` %s does not contain any PEM encoded certificates", conf.cacertFile)
+			}
+			return nil, errors.New("`cacert_file` is neither an existing file nor PEM encoded certificates")
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+
+	// If configured as insecure, turn off SSL verification
+	if conf.insecure {
+		tlsConfig.InsecureSkipVerify = true
+	} else if conf.hostOverride != "" {
+		tlsConfig.ServerName = conf.hostOverride
+	}
+
+	return tlsConfig, nil
+}
+
 type withHeader struct {
 	http.Header
 	hostOverride string
@@ -22,9 +241,318 @@ func (h withHeader) RoundTrip(req *http.Request) (*http.Response, error) {
 	for k, v := range h.Header {
 		req.Header[k] = v
 	}
+	if id, ok := req.Context().Value(opaqueIDKey{}).(string); ok && req.Header.Get("X-Opaque-Id") == "" {
+		req.Header.Set("X-Opaque-Id", id)
+	}
 	if h.hostOverride != "" {
 		req.Host = h.hostOverride
 	}
 
 	return h.rt.RoundTrip(req)
 }
+
+// readOnlyPaths match the paths of POST requests which only read from the
+// cluster and are allowed in read only mode. The endpoint must end the path,
+// preceded only by index names, so e.g. storing a script or document with
+// the ID `_search` is still rejected.
+var readOnlyPaths = []*regexp.Regexp{
+	regexp.MustCompile(`^(/[^_/][^/]*)?/(_count|_field_caps|_mget|_msearch|_search)$`),
+	regexp.MustCompile(`^/[^_/][^/]*/_explain/[^/]+$`),
+	regexp.MustCompile(`^/_ingest/pipeline/([^_/][^/]*/)?_simulate$`),
+	regexp.MustCompile(`^/_index_template/(_simulate(/[^/]+)?|_simulate_index/[^/]+)$`),
+	regexp.MustCompile(`^/_security/user/([^_/][^/]*/)?_has_privileges$`),
+}
+
+// readOnlyError is returned for requests which would modify the cluster when
+// `read_only` is set.
+type readOnlyError struct {
+	method   string
+	path     string
+	resource string
+}
+
+func (e *readOnlyError) Error() string {
+	msg := fmt.Sprintf("provider is read_only, refusing to send %s %s", e.method, e.path)
+	if e.resource != "" {
+		msg += " for " + e.resource
+	}
+	return msg
+}
+
+// readOnlyTransport rejects requests which could modify the cluster, so a
+// plan can be run safely with credentials that are allowed to write.
+type readOnlyTransport struct {
+	rt http.RoundTripper
+}
+
+// isReadRequest reports whether a request only reads from the cluster.
+func isReadRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	case http.MethodPost:
+		for _, path := range readOnlyPaths {
+			if path.MatchString(req.URL.Path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (t readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if isReadRequest(req) {
+		return t.rt.RoundTrip(req)
+	}
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	resource, _ := req.Context().Value(opaqueIDKey{}).(string)
+	return nil, &readOnlyError{
+		method:   req.Method,
+		path:     req.URL.Path,
+		resource: strings.TrimPrefix(resource, "terraform/"),
+	}
+}
+
+// requestLimiter bounds the number of concurrent requests, and separately
+// the number of concurrent write requests, e.g. to avoid tripping circuit
+// breakers of small clusters. A nil semaphore means no limit.
+type requestLimiter struct {
+	all    chan struct{}
+	writes chan struct{}
+}
+
+func newRequestLimiter(maxRequests, maxWrites int) *requestLimiter {
+	if maxRequests <= 0 && maxWrites <= 0 {
+		return nil
+	}
+
+	l := &requestLimiter{}
+	if maxRequests > 0 {
+		l.all = make(chan struct{}, maxRequests)
+	}
+	if maxWrites > 0 {
+		l.writes = make(chan struct{}, maxWrites)
+	}
+	return l
+}
+
+// acquire waits for a slot for the request, returning a function releasing
+// it, or an error if the request is cancelled while waiting.
+func (l *requestLimiter) acquire(req *http.Request) (func(), error) {
+	var acquired []chan struct{}
+	release := func() {
+		for _, sem := range acquired {
+			<-sem
+		}
+	}
+
+	semaphores := []chan struct{}{l.all}
+	if !isReadRequest(req) {
+		// acquire the write slot first so waiting writes don't hold slots
+		// reads could use
+		semaphores = []chan struct{}{l.writes, l.all}
+	}
+	for _, sem := range semaphores {
+		if sem == nil {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+			acquired = append(acquired, sem)
+		case <-req.Context().Done():
+			release()
+			return nil, req.Context().Err()
+		}
+	}
+	return release, nil
+}
+
+// limitTransport holds a slot of the limiter until the response body is
+// closed.
+type limitTransport struct {
+	rt      http.RoundTripper
+	limiter *requestLimiter
+}
+
+func (t limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := t.limiter.acquire(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := t.rt.RoundTrip(req)
+	if err != nil || res.Body == nil {
+		release()
+		return res, err
+	}
+	res.Body = &releaseOnClose{ReadCloser: res.Body, release: release}
+	return res, nil
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
+type opaqueIDKey struct{}
+
+// withOpaqueID wraps the CRUD functions of a resource so the requests they
+// make carry an X-Opaque-Id header identifying the resource, e.g.
+// `terraform/elasticsearch_index/my-index`. Elasticsearch includes it in slow
+// logs, audit logs and the tasks API. Terraform doesn't tell providers the
+// address of a resource, so the resource type and ID are used instead.
+func withOpaqueID(name string, r *schema.Resource) {
+	opaqueID := func(ctx context.Context, d *schema.ResourceData) context.Context {
+		id := "terraform/" + name
+		if d.Id() != "" {
+			id += "/" + d.Id()
+		}
+		return context.WithValue(ctx, opaqueIDKey{}, id)
+	}
+
+	if fn := r.CreateContext; fn != nil {
+		r.CreateContext = func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			return fn(opaqueID(ctx, d), d, meta)
+		}
+	}
+	if fn := r.ReadContext; fn != nil {
+		r.ReadContext = func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			return fn(opaqueID(ctx, d), d, meta)
+		}
+	}
+	if fn := r.UpdateContext; fn != nil {
+		r.UpdateContext = func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			return fn(opaqueID(ctx, d), d, meta)
+		}
+	}
+	if fn := r.DeleteContext; fn != nil {
+		r.DeleteContext = func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			return fn(opaqueID(ctx, d), d, meta)
+		}
+	}
+}
+
+// endpointTransport records which of the configured urls answered the last
+// request. With failover enabled, requests are addressed to the first url and
+// retried in order against the others when they can't be sent.
+type endpointTransport struct {
+	rt       http.RoundTripper
+	urls     []*url.URL
+	rawUrls  []string
+	failover bool
+
+	mu     sync.Mutex
+	active string
+}
+
+func newEndpointTransport(rt http.RoundTripper, rawUrls []string, failover bool) *endpointTransport {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	t := &endpointTransport{rt: rt, failover: failover}
+	for _, rawUrl := range rawUrls {
+		u, err := url.Parse(rawUrl)
+		if err != nil {
+			log.Printf("[WARN] ignoring invalid url %s: %+v", rawUrl, err)
+			continue
+		}
+		t.urls = append(t.urls, u)
+		t.rawUrls = append(t.rawUrls, rawUrl)
+	}
+	return t
+}
+
+func (t *endpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.failover || len(t.urls) < 2 {
+		res, err := t.rt.RoundTrip(req)
+		if err == nil {
+			t.setActive(req.URL)
+		}
+		return res, err
+	}
+
+	// buffer the body so it can be replayed against each url
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		res *http.Response
+		err error
+	)
+	for i, target := range t.urls {
+		// don't fail over requests which were cancelled or timed out
+		if i > 0 && req.Context().Err() != nil {
+			break
+		}
+
+		r := t.redirect(req, target, body)
+		if i > 0 {
+			log.Printf("[INFO] Failing over to %s", target.Redacted())
+		}
+
+		res, err = t.rt.RoundTrip(r)
+		if err == nil {
+			t.setActive(r.URL)
+			return res, nil
+		}
+		log.Printf("[WARN] Request to %s failed: %+v", target.Redacted(), err)
+	}
+
+	return res, err
+}
+
+// redirect clones a request addressed to the first url so it's addressed to
+// the target url instead.
+func (t *endpointTransport) redirect(req *http.Request, target *url.URL, body []byte) *http.Request {
+	r := req.Clone(req.Context())
+	if body != nil {
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	if target == t.urls[0] {
+		return r
+	}
+
+	r.URL.Scheme = target.Scheme
+	r.URL.Host = target.Host
+	r.URL.User = target.User
+	r.URL.Path = strings.TrimSuffix(target.Path, "/") + strings.TrimPrefix(req.URL.Path, strings.TrimSuffix(t.urls[0].Path, "/"))
+	r.URL.RawPath = ""
+	r.Host = ""
+	return r
+}
+
+func (t *endpointTransport) setActive(u *url.URL) {
+	for i, configured := range t.urls {
+		if configured.Scheme == u.Scheme && configured.Host == u.Host {
+			t.mu.Lock()
+			t.active = t.rawUrls[i]
+			t.mu.Unlock()
+			return
+		}
+	}
+}
+
+// Active returns the url that answered the last request.
+func (t *endpointTransport) Active() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.active
+}
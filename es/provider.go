@@ -3,7 +3,8 @@ package es
 import (
 	"context"
 	"crypto/tls"
-	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -12,18 +13,19 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	awscredentials "github.com/aws/aws-sdk-go/aws/credentials"
 	awsstscreds "github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	awssession "github.com/aws/aws-sdk-go/aws/session"
-	awssigv4 "github.com/aws/aws-sdk-go/aws/signer/v4"
 	awssts "github.com/aws/aws-sdk-go/service/sts"
-	"github.com/deoxxa/aws_signing_client"
 	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic6 "gopkg.in/olivere/elastic.v6"
@@ -39,10 +41,23 @@ const (
 	OpenSearch
 )
 
+var (
+	minimalESVersion      = version.Must(version.NewVersion("6.0.0"))
+	es6VersionConstraints = mustVersionConstraints(">= 6.0, < 7.0")
+)
+
 var awsUrlRegexp = regexp.MustCompile(`([a-z0-9-]+).es.amazonaws.com$`)
+var aossUrlRegexp = regexp.MustCompile(`([a-z0-9-]+).aoss.amazonaws.com$`)
+
+const (
+	urlStrategyRoundRobin = "round_robin"
+	urlStrategyFailover   = "failover"
+)
 
 type ProviderConf struct {
 	rawUrl                   string
+	urls                     []string
+	urlStrategy              string
 	insecure                 bool
 	sniffing                 bool
 	healthchecking           bool
@@ -59,34 +74,153 @@ type ProviderConf struct {
 	awsAssumeRoleArn         string
 	awsAssumeRoleExternalID  string
 	awsAssumeRoleSessionName string
+	awsWebIdentityTokenFile  string
+	awsAssumeRoleDuration    time.Duration
+	awsAssumeRolePolicy      string
+	awsStsEndpoint           string
 	awsAccessKeyId           string
 	awsSecretAccessKey       string
 	awsSessionToken          string
 	awsSig4Service           string
+	serverless               bool
 	awsProfile               string
 	certPemPath              string
 	keyPemPath               string
 	kibanaUrl                string
 	hostOverride             string
+	maxRetries               int
+	retryBackoffMinMs        int
+	retryBackoffMaxMs        int
+	retryStatusCodes         []int
+	requestTimeout           time.Duration
+	readOnly                 bool
+	requestLog               *requestLogger
+	requestLimiter           *requestLimiter
+	credentials              *credentialsSource
+	proxyUrl                 *url.URL
+	kibana                   kibanaConf
+	headers                  map[string]string
 	// determined after connecting to the server
 	flavor ServerFlavor
+
+	// clients are built lazily on first use and shared by all resources and
+	// data sources of this provider instance
+	clientMu       sync.Mutex
+	client         interface{}
+	kibanaClientMu sync.Mutex
+	kibanaClient   interface{}
+	// endpoints tracks which of the urls answered the last request
+	endpoints *endpointTransport
+}
+
+// kibanaConf holds the settings of the Kibana client which differ from the
+// Elasticsearch ones, unset settings fall back to the Elasticsearch settings.
+type kibanaConf struct {
+	username    string
+	password    string
+	token       string
+	tokenName   string
+	cacertFile  string
+	certPemPath string
+	keyPemPath  string
+	insecure    bool
+	headers     map[string]string
+	credentials *credentialsSource
 }
 
 func Provider() *schema.Provider {
-	return &schema.Provider{
+	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"url": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("ELASTICSEARCH_URL", nil),
 				Description: "Elasticsearch URL",
 			},
+			"urls": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "A list of Elasticsearch URLs of the same cluster, used instead of `url`. Defaults to the comma separated `ELASTICSEARCH_URLS` from the environment if `url` is not set either.",
+			},
+			"urls_strategy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      urlStrategyRoundRobin,
+				ValidateFunc: validation.StringInSlice([]string{urlStrategyRoundRobin, urlStrategyFailover}, false),
+				Description:  "How requests are distributed over `urls`: `round_robin` spreads them across all healthy urls, `failover` sends them to the first url that can be reached, in order.",
+			},
+			"cloud_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ELASTICSEARCH_CLOUD_ID", nil),
+				Description: "Elastic Cloud deployment ID, used to determine `url` and `kibana_url` when they're not set",
+			},
 			"kibana_url": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("KIBANA_URL", nil),
 				Description: "URL to reach the Kibana API",
 			},
+			"kibana": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Settings of the Kibana client, unset ones fall back to the Elasticsearch settings",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"username": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Username to use to connect to Kibana using basic auth",
+						},
+						"password": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "Password to use to connect to Kibana using basic auth, requires `username`",
+						},
+						"token": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "A bearer token or ApiKey for an Authorization header",
+						},
+						"token_name": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "ApiKey",
+							Description: "The type of token, usually ApiKey or Bearer",
+						},
+						"cacert_file": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "A Custom CA certificate",
+						},
+						"client_cert_path": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "A X509 certificate to connect to Kibana",
+						},
+						"client_key_path": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "A X509 key to connect to Kibana",
+						},
+						"insecure": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Description: "Disable SSL verification of API calls",
+						},
+						"headers": {
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Additional headers sent with every request to Kibana",
+						},
+					},
+				},
+			},
 			"sniff": {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -117,12 +251,49 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("ELASTICSEARCH_TOKEN", nil),
 				Description: "A bearer token or ApiKey for an Authorization header, e.g. Active Directory API key.",
 			},
+			"api_key_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("ELASTICSEARCH_API_KEY_ID", nil),
+				ConflictsWith: []string{"token"},
+				RequiredWith:  []string{"api_key_secret"},
+				Description:   "The id of an API key, used with `api_key_secret` for an ApiKey Authorization header",
+			},
+			"api_key_secret": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				DefaultFunc:  schema.EnvDefaultFunc("ELASTICSEARCH_API_KEY_SECRET", nil),
+				RequiredWith: []string{"api_key_id"},
+				Description:  "The secret of the API key identified by `api_key_id`",
+			},
 			"token_name": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Default:     "ApiKey",
 				Description: "The type of token, usually ApiKey or Bearer",
 			},
+			"password_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("ELASTICSEARCH_PASSWORD_FILE", nil),
+				ConflictsWith: []string{"password", "token_file"},
+				Description:   "File containing the password to use with `username`, read again when it changes",
+			},
+			"token_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("ELASTICSEARCH_TOKEN_FILE", nil),
+				ConflictsWith: []string{"token", "api_key_id"},
+				Description:   "File containing a token to use with `token_name`, read again when it changes",
+			},
+			"credentials_command": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"password", "token", "api_key_id", "password_file", "token_file"},
+				Description:   "Command and arguments of a helper printing credentials as JSON, with either a `token` and optionally `token_name`, or a `password` and optionally `username`, and an optional RFC 3339 `expiration`. It is run again when the credentials expire or are rejected.",
+			},
 			"aws_assume_role_arn": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -141,6 +312,32 @@ func Provider() *schema.Provider {
 				Default:     "",
 				Description: "AWS IAM session name to use when assuming a role.",
 			},
+			"aws_assume_role_web_identity_token_file": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				RequiredWith: []string{"aws_assume_role_arn"},
+				Description:  "File containing an OIDC token to assume `aws_assume_role_arn` with, e.g. the token of an EKS service account. The file is read again when the credentials expire.",
+			},
+			"aws_assume_role_duration": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(900, 43200),
+				Description:  "Duration in seconds of the credentials of the assumed role, defaults to 15 minutes, or one hour with a web identity token.",
+			},
+			"aws_assume_role_policy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				ValidateFunc: validation.StringIsJSON,
+				Description:  "IAM policy in JSON format further restricting the permissions of the assumed role.",
+			},
+			"aws_sts_endpoint": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "Custom endpoint of the AWS STS API used to assume roles, e.g. a VPC endpoint or a local stand-in like LocalStack.",
+			},
 			"aws_access_key": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -206,8 +403,7 @@ func Provider() *schema.Provider {
 			"aws_signature_service": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Default:     "es",
-				Description: "AWS service name used in the credential scope of signed requests to ElasticSearch.",
+				Description: "AWS service name used in the credential scope of signed requests to ElasticSearch. Defaults to `aoss` for OpenSearch Serverless collections and `es` otherwise.",
 			},
 			"elasticsearch_version": {
 				Type:        schema.TypeString,
@@ -224,6 +420,88 @@ func Provider() *schema.Provider {
 				Default:     5,
 				Description: "Version ping timeout in seconds",
 			},
+			"proxy_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Proxy to send requests through, e.g. `http://proxy:3128` or `socks5://bastion:1080`. Defaults to the `HTTP_PROXY`, `HTTPS_PROXY` and `NO_PROXY` environment variables",
+			},
+			"request_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Timeout in seconds of a single request to the cluster, 0 means requests are only bound by the resource timeouts",
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of times a request is retried on a connection error or a status code in `retry_status_codes`, 0 disables retries",
+			},
+			"retry_backoff_min_ms": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Initial wait between retries in milliseconds, doubled on every retry",
+			},
+			"retry_backoff_max_ms": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      30000,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum wait between retries in milliseconds",
+			},
+			"retry_status_codes": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt, ValidateFunc: validation.IntBetween(100, 599)},
+				Description: "HTTP status codes of responses that are retried, defaults to 429, 502, 503 and 504. GET, HEAD, PUT and DELETE requests are retried on any of them, other requests such as POST only on 429 and 503, and on connection errors only if they weren't sent, so requests the cluster may have applied aren't sent twice",
+			},
+			"headers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Additional headers sent with every request to Elasticsearch and Kibana",
+			},
+			"request_log_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Path of a file to append a JSON line to for every request, with its method, path, status, duration and resource. Sensitive fields are redacted.",
+			},
+			"request_log_redact_fields": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Additional body fields and headers to redact from the request log, `password`, `password_hash`, `license`, `access_key`, `secret_key`, `session_token`, `client_secret`, `token`, `Authorization` and `X-Amz-Security-Token` are always redacted.",
+			},
+			"max_concurrent_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of concurrent requests to Elasticsearch and Kibana, 0 means unlimited",
+			},
+			"max_concurrent_write_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of concurrent requests that modify the cluster, 0 means unlimited",
+			},
+			"validate_credentials": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ELASTICSEARCH_VALIDATE_CREDENTIALS", false),
+				Description: "Check the credentials when the provider is configured, failing before any resource is planned if they are rejected",
+			},
+			"read_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ELASTICSEARCH_READ_ONLY", false),
+				Description: "Reject any request that could modify the cluster, e.g. to run plans with credentials that are allowed to write",
+			},
 			"host_override": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -271,6 +549,7 @@ func Provider() *schema.Provider {
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
+			"elasticsearch_current_identity":       dataSourceElasticsearchCurrentIdentity(),
 			"elasticsearch_host":                   dataSourceElasticsearchHost(),
 			"elasticsearch_opendistro_destination": dataSourceElasticsearchOpenDistroDestination(),
 			"elasticsearch_opensearch_destination": dataSourceOpenSearchDestination(),
@@ -278,36 +557,146 @@ func Provider() *schema.Provider {
 
 		ConfigureContextFunc: providerConfigure,
 	}
+
+	for name, r := range provider.ResourcesMap {
+		withOpaqueID(name, r)
+		withWarnings(r)
+	}
+	for name, r := range provider.DataSourcesMap {
+		withOpaqueID(name, r)
+		withWarnings(r)
+	}
+
+	return provider
 }
 
 func providerConfigure(c context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-	rawUrl := d.Get("url").(string)
+	urls := expandStringList(d.Get("urls").([]interface{}))
+	if len(urls) == 0 {
+		if rawUrl := d.Get("url").(string); rawUrl != "" {
+			urls = []string{rawUrl}
+		}
+	}
+	if len(urls) == 0 {
+		for _, u := range strings.Split(os.Getenv("ELASTICSEARCH_URLS"), ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				urls = append(urls, u)
+			}
+		}
+	}
+	kibanaUrl := d.Get("kibana_url").(string)
+	if cloudID := d.Get("cloud_id").(string); cloudID != "" {
+		esUrl, cloudKibanaUrl, err := decodeCloudID(cloudID)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		if len(urls) == 0 {
+			urls = []string{esUrl}
+		}
+		if kibanaUrl == "" {
+			kibanaUrl = cloudKibanaUrl
+		}
+	}
+	if len(urls) == 0 {
+		return nil, diag.Errorf("one of `url`, `urls` or `cloud_id` must be set")
+	}
+
+	token := d.Get("token").(string)
+	tokenName := d.Get("token_name").(string)
+	if apiKeyID := d.Get("api_key_id").(string); apiKeyID != "" {
+		token = base64.StdEncoding.EncodeToString([]byte(apiKeyID + ":" + d.Get("api_key_secret").(string)))
+		tokenName = "ApiKey"
+	}
+
+	retryStatusCodes := defaultRetryStatusCodes
+	if v, ok := d.GetOk("retry_status_codes"); ok {
+		retryStatusCodes = nil
+		for _, code := range v.([]interface{}) {
+			retryStatusCodes = append(retryStatusCodes, code.(int))
+		}
+	}
+	if d.Get("retry_backoff_min_ms").(int) > d.Get("retry_backoff_max_ms").(int) {
+		return nil, diag.Errorf("`retry_backoff_min_ms` must not be greater than `retry_backoff_max_ms`")
+	}
+
+	var proxyUrl *url.URL
+	if rawProxyUrl := d.Get("proxy_url").(string); rawProxyUrl != "" {
+		var err error
+		proxyUrl, err = url.Parse(rawProxyUrl)
+		if err != nil {
+			return nil, diag.Errorf("invalid `proxy_url`: %+v", err)
+		}
+		switch proxyUrl.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, diag.Errorf("invalid `proxy_url` scheme %q, must be one of http, https, socks5", proxyUrl.Scheme)
+		}
+	}
+
+	var requestLog *requestLogger
+	if path := d.Get("request_log_file").(string); path != "" {
+		var redactFields []string
+		for _, field := range d.Get("request_log_redact_fields").([]interface{}) {
+			redactFields = append(redactFields, field.(string))
+		}
+		var err error
+		requestLog, err = newRequestLogger(path, redactFields)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+	}
+
+	rawUrl := urls[0]
 	parsedUrl, err := url.Parse(rawUrl)
 	if err != nil {
 		return nil, diag.FromErr(err)
 	}
 
-	return &ProviderConf{
+	awsSig4Service := d.Get("aws_signature_service").(string)
+	if awsSig4Service == "" {
+		awsSig4Service = "es"
+		if aossUrlRegexp.MatchString(parsedUrl.Hostname()) {
+			awsSig4Service = "aoss"
+		}
+	}
+
+	conf := &ProviderConf{
 		rawUrl:             rawUrl,
-		kibanaUrl:          d.Get("kibana_url").(string),
+		urls:               urls,
+		urlStrategy:        d.Get("urls_strategy").(string),
+		kibanaUrl:          kibanaUrl,
 		insecure:           d.Get("insecure").(bool),
 		sniffing:           d.Get("sniff").(bool),
 		healthchecking:     d.Get("healthcheck").(bool),
 		cacertFile:         d.Get("cacert_file").(string),
 		username:           d.Get("username").(string),
 		password:           d.Get("password").(string),
-		token:              d.Get("token").(string),
-		tokenName:          d.Get("token_name").(string),
+		token:              token,
+		tokenName:          tokenName,
 		parsedUrl:          parsedUrl,
 		signAWSRequests:    d.Get("sign_aws_requests").(bool),
-		awsSig4Service:     d.Get("aws_signature_service").(string),
+		awsSig4Service:     awsSig4Service,
+		serverless:         awsSig4Service == "aoss",
 		esVersion:          d.Get("elasticsearch_version").(string),
 		pingTimeoutSeconds: d.Get("version_ping_timeout").(int),
+		proxyUrl:           proxyUrl,
+		requestTimeout:     time.Duration(d.Get("request_timeout").(int)) * time.Second,
+		readOnly:           d.Get("read_only").(bool),
+		requestLog:         requestLog,
+		requestLimiter:     newRequestLimiter(d.Get("max_concurrent_requests").(int), d.Get("max_concurrent_write_requests").(int)),
+		maxRetries:         d.Get("max_retries").(int),
+		retryBackoffMinMs:  d.Get("retry_backoff_min_ms").(int),
+		retryBackoffMaxMs:  d.Get("retry_backoff_max_ms").(int),
+		retryStatusCodes:   retryStatusCodes,
 		awsRegion:          d.Get("aws_region").(string),
 
 		awsAssumeRoleArn:         d.Get("aws_assume_role_arn").(string),
 		awsAssumeRoleExternalID:  d.Get("aws_assume_role_external_id").(string),
 		awsAssumeRoleSessionName: d.Get("aws_assume_role_session_name").(string),
+		awsWebIdentityTokenFile:  d.Get("aws_assume_role_web_identity_token_file").(string),
+		awsAssumeRoleDuration:    time.Duration(d.Get("aws_assume_role_duration").(int)) * time.Second,
+		awsAssumeRolePolicy:      d.Get("aws_assume_role_policy").(string),
+		awsStsEndpoint:           d.Get("aws_sts_endpoint").(string),
 		awsAccessKeyId:           d.Get("aws_access_key").(string),
 		awsSecretAccessKey:       d.Get("aws_secret_key").(string),
 		awsSessionToken:          d.Get("aws_token").(string),
@@ -315,45 +704,88 @@ func providerConfigure(c context.Context, d *schema.ResourceData) (interface{},
 		certPemPath:              d.Get("client_cert_path").(string),
 		keyPemPath:               d.Get("client_key_path").(string),
 		hostOverride:             d.Get("host_override").(string),
-	}, nil
+	}
+	if credentials := expandCredentialsSource(d, conf); credentials != nil {
+		if _, err := credentials.authorization(false); err != nil {
+			return nil, diag.FromErr(err)
+		}
+		conf.credentials = credentials
+	}
+	for name, value := range d.Get("headers").(map[string]interface{}) {
+		if conf.headers == nil {
+			conf.headers = map[string]string{}
+		}
+		conf.headers[name] = value.(string)
+	}
+	kibana, err := expandKibanaConf(d, conf)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	conf.kibana = kibana
+
+	// Validate certificates up front rather than on the first request
+	if _, err := newTLSConfig(conf); err != nil {
+		return nil, diag.FromErr(err)
+	}
+	if conf.kibanaUrl != "" {
+		kconf, err := conf.kibanaProviderConf()
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		if _, err := newTLSConfig(kconf); err != nil {
+			return nil, diag.Errorf("invalid `kibana` TLS settings: %+v", err)
+		}
+	}
+
+	if d.Get("validate_credentials").(bool) {
+		id, err := currentIdentity(c, conf)
+		if err != nil {
+			return nil, diag.Errorf("error validating credentials: %+v", err)
+		}
+		log.Printf("[INFO] Authenticated as %s", id.Username)
+	}
+
+	return conf, nil
 }
 
+// getClient returns the Elasticsearch client for the provider, creating it
+// and detecting the cluster version and flavor on the first call.
 func getClient(conf *ProviderConf) (interface{}, error) {
+	conf.clientMu.Lock()
+	defer conf.clientMu.Unlock()
+
+	if conf.client != nil {
+		return conf.client, nil
+	}
+
+	client, err := newClient(conf)
+	if err != nil {
+		return nil, err
+	}
+	conf.client = client
+
+	return client, nil
+}
+
+func newClient(conf *ProviderConf) (interface{}, error) {
 	opts := []elastic7.ClientOptionFunc{
-		elastic7.SetURL(conf.rawUrl),
+		elastic7.SetURL(conf.clientUrls()...),
 		elastic7.SetScheme(conf.parsedUrl.Scheme),
 		elastic7.SetSniff(conf.sniffing),
-		elastic7.SetHealthcheck(conf.healthchecking),
+		// serverless collections don't provide the node APIs used by health checks
+		elastic7.SetHealthcheck(conf.healthchecking && !conf.serverless),
 	}
 
-	if conf.parsedUrl.User.Username() != "" {
-		p, _ := conf.parsedUrl.User.Password()
-		opts = append(opts, elastic7.SetBasicAuth(conf.parsedUrl.User.Username(), p))
+	httpClient, sniffable, err := esHttpClient(conf)
+	if err != nil {
+		return nil, err
 	}
-	if conf.username != "" && conf.password != "" {
-		opts = append(opts, elastic7.SetBasicAuth(conf.username, conf.password))
+	opts = append(opts, elastic7.SetHttpClient(httpClient))
+	if !sniffable {
+		opts = append(opts, elastic7.SetSniff(false))
 	}
-
-	if m := awsUrlRegexp.FindStringSubmatch(conf.parsedUrl.Hostname()); m != nil && conf.signAWSRequests {
-		log.Printf("[INFO] Using AWS: %+v", m[1])
-		client, err := awsHttpClient(m[1], conf, map[string]string{})
-		if err != nil {
-			return nil, err
-		}
-		opts = append(opts, elastic7.SetHttpClient(client), elastic7.SetSniff(false))
-	} else if awsRegion := conf.awsRegion; conf.awsRegion != "" && conf.signAWSRequests {
-		log.Printf("[INFO] Using AWS: %+v", awsRegion)
-		client, err := awsHttpClient(awsRegion, conf, map[string]string{})
-		if err != nil {
-			return nil, err
-		}
-		opts = append(opts, elastic7.SetHttpClient(client), elastic7.SetSniff(false))
-	} else if conf.insecure || conf.cacertFile != "" {
-		opts = append(opts, elastic7.SetHttpClient(tlsHttpClient(conf, map[string]string{})), elastic7.SetSniff(false))
-	} else if conf.token != "" {
-		opts = append(opts, elastic7.SetHttpClient(tokenHttpClient(conf, map[string]string{})), elastic7.SetSniff(false))
-	} else {
-		opts = append(opts, elastic7.SetHttpClient(defaultHttpClient(conf, map[string]string{})))
+	if r := newRetrier(conf); r != nil {
+		opts = append(opts, elastic7.SetRetrier(r), elastic7.SetRetryStatusCodes(conf.retryStatusCodes...))
 	}
 
 	logProviderLevel, ok := os.LookupEnv("TF_LOG_PROVIDER")
@@ -398,73 +830,67 @@ func getClient(conf *ProviderConf) (interface{}, error) {
 	}
 	relevantClient = client
 
+	// Serverless collections don't report a version, they are compatible with
+	// the OpenSearch 2.x API
+	if conf.esVersion == "" && conf.serverless {
+		log.Printf("[INFO] Using OpenSearch Serverless")
+		conf.esVersion = "2.0.0"
+		conf.flavor = OpenSearch
+	}
+
 	// Use the v7 client to ping the cluster to determine the version if one was not provided
 	if conf.esVersion == "" {
 		log.Printf("[INFO] Pinging url to determine version %+v with timeout %ds", conf.rawUrl, conf.pingTimeoutSeconds)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(conf.pingTimeoutSeconds)*time.Second)
 		defer cancel()
-		info, httpStatus, err := client.Ping(conf.rawUrl).Do(ctx)
-		if httpStatus == http.StatusForbidden {
+		info, err := pingServerInfo(ctx, client)
+		if elastic7.IsForbidden(err) {
 			return nil, errors.New("HTTP 403 Forbidden: Permission denied. Please ensure that the correct credentials are being used to access the cluster.")
 		}
 		if err != nil {
 			// Replace the timeout error because it gives no context
-			if os.IsTimeout(err) {
+			if os.IsTimeout(err) || errors.Is(err, context.DeadlineExceeded) {
 				err = fmt.Errorf("timeout after %d seconds while pinging '%+v' to determine server version, please consider setting 'elasticsearch_version' to avoid this lookup", conf.pingTimeoutSeconds, conf.rawUrl)
 			}
 
 			return nil, err
 		}
 		conf.esVersion = info.Version.Number
+		conf.flavor = info.flavor()
+		log.Printf("[INFO] ES version %+v, flavor %v", info.Version, conf.flavor)
+	}
 
-		// if upstream library exposes support for OpenSearch's distribution
-		// param, we can use that as well
-		log.Printf("[INFO] ES version %+v", info.Version)
-		switch info.Version.BuildFlavor {
-		case "default":
-			conf.flavor = Elasticsearch
-		case "oss":
-			conf.flavor = ElasticsearchOpenSource
-		}
+	elasticVersion, err := version.NewVersion(conf.esVersion)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse ElasticSearch version %q: %w", conf.esVersion, err)
 	}
 
-	if conf.esVersion < "7.0.0" && conf.esVersion >= "6.0.0" {
+	if isOpenSearch(conf, elasticVersion) {
+		// OpenSearch is API compatible with 7.x of ES, nothing to do.
+		log.Printf("[INFO] Using OpenSearch %s", elasticVersion)
+	} else if es6VersionConstraints.Check(elasticVersion) {
 		log.Printf("[INFO] Using ES 6")
 		opts := []elastic6.ClientOptionFunc{
-			elastic6.SetURL(conf.rawUrl),
+			elastic6.SetURL(conf.clientUrls()...),
 			elastic6.SetScheme(conf.parsedUrl.Scheme),
 			elastic6.SetSniff(conf.sniffing),
 			elastic6.SetHealthcheck(conf.healthchecking),
 		}
 
-		if conf.parsedUrl.User.Username() != "" {
-			p, _ := conf.parsedUrl.User.Password()
-			opts = append(opts, elastic6.SetBasicAuth(conf.parsedUrl.User.Username(), p))
+		// the v6 client only retries connection errors, status codes are
+		// retried by the transport
+		if r := newRetrier(conf); r != nil {
+			opts = append(opts, elastic6.SetRetrier(r))
+			httpClient = &http.Client{Transport: statusRetryTransport{
+				rt:          httpClient.Transport,
+				retrier:     r,
+				statusCodes: conf.retryStatusCodes,
+				timeout:     httpClient.Timeout,
+			}}
 		}
-		if conf.username != "" && conf.password != "" {
-			opts = append(opts, elastic6.SetBasicAuth(conf.username, conf.password))
-		}
-
-		if m := awsUrlRegexp.FindStringSubmatch(conf.parsedUrl.Hostname()); m != nil && conf.signAWSRequests {
-			log.Printf("[INFO] Using AWS: %+v", m[1])
-			client, err := awsHttpClient(m[1], conf, map[string]string{})
-			if err != nil {
-				return nil, err
-			}
-			opts = append(opts, elastic6.SetHttpClient(client), elastic6.SetSniff(false))
-		} else if awsRegion := conf.awsRegion; conf.awsRegion != "" && conf.signAWSRequests {
-			log.Printf("[INFO] Using AWS: %+v", conf.awsRegion)
-			client, err := awsHttpClient(awsRegion, conf, map[string]string{})
-			if err != nil {
-				return nil, err
-			}
-			opts = append(opts, elastic6.SetHttpClient(client), elastic6.SetSniff(false))
-		} else if conf.insecure || conf.cacertFile != "" {
-			opts = append(opts, elastic6.SetHttpClient(tlsHttpClient(conf, map[string]string{})), elastic6.SetSniff(false))
-		} else if conf.token != "" {
-			opts = append(opts, elastic6.SetHttpClient(tokenHttpClient(conf, map[string]string{})), elastic6.SetSniff(false))
-		} else {
-			opts = append(opts, elastic6.SetHttpClient(defaultHttpClient(conf, map[string]string{})))
+		opts = append(opts, elastic6.SetHttpClient(httpClient))
+		if !sniffable {
+			opts = append(opts, elastic6.SetSniff(false))
 		}
 
 		switch logProviderLevel {
@@ -491,21 +917,265 @@ func getClient(conf *ProviderConf) (interface{}, error) {
 		if err != nil {
 			return nil, err
 		}
-	} else if conf.flavor == Unknown && conf.esVersion < "2.0.0" && conf.esVersion >= "1.0.0" {
-		// Version 1.x of OpenSearch very likely. Nothing to do since it's API
-		// compatible with 7.x of ES. If elastic client library supports detecting
-		// flavor, update to Opensearch.
-	} else if conf.esVersion < "6.0.0" {
+	} else if elasticVersion.LessThan(minimalESVersion) {
 		return nil, fmt.Errorf("ElasticSearch version %s is older than 6.0.0 and is not supported, flavor: %v.", conf.esVersion, conf.flavor)
 	}
 
 	return relevantClient, nil
 }
 
+// esHttpClient builds the HTTP client shared by the elasticsearch clients. The
+// second return value reports whether the client supports node sniffing.
+func esHttpClient(conf *ProviderConf) (*http.Client, bool, error) {
+	awsRegion := awsSigningRegion(conf)
+	client, err := newHttpClient(conf, conf.headers, awsRegion)
+	if err != nil {
+		return nil, false, err
+	}
+	// nodes discovered by sniffing are usually not reachable for hosted
+	// clusters, which is what AWS, custom CAs and tokens indicate
+	sniffable := awsRegion == "" && !conf.insecure && conf.cacertFile == "" && conf.token == "" && conf.credentials == nil
+
+	conf.endpoints = newEndpointTransport(client.Transport, conf.urls, conf.urlStrategy == urlStrategyFailover)
+	client.Transport = conf.endpoints
+	if conf.readOnly {
+		client.Transport = readOnlyTransport{rt: client.Transport}
+	}
+
+	return client, sniffable, nil
+}
+
+// clientUrls returns the urls the elastic clients are configured with. With
+// the failover strategy requests are always addressed to the first url and
+// the endpoint transport retries them against the others.
+func (conf *ProviderConf) clientUrls() []string {
+	if conf.urlStrategy == urlStrategyFailover {
+		return conf.urls[:1]
+	}
+	return conf.urls
+}
+
+// expandCredentialsSource returns the source of credentials loaded from files
+// or a command, if any are configured.
+func expandCredentialsSource(d *schema.ResourceData, conf *ProviderConf) *credentialsSource {
+	source := &credentialsSource{
+		passwordFile: d.Get("password_file").(string),
+		tokenFile:    d.Get("token_file").(string),
+		username:     conf.username,
+		tokenName:    conf.tokenName,
+	}
+	for _, arg := range d.Get("credentials_command").([]interface{}) {
+		source.command = append(source.command, arg.(string))
+	}
+
+	if source.passwordFile == "" && source.tokenFile == "" && len(source.command) == 0 {
+		return nil
+	}
+	return source
+}
+
+// expandKibanaConf reads the `kibana` block, falling back to the
+// Elasticsearch settings. Credentials fall back as a whole, so a Kibana token
+// isn't sent along with the Elasticsearch basic auth or vice versa.
+func expandKibanaConf(d *schema.ResourceData, conf *ProviderConf) (kibanaConf, error) {
+	k := kibanaConf{
+		username:    conf.username,
+		password:    conf.password,
+		token:       conf.token,
+		tokenName:   conf.tokenName,
+		cacertFile:  conf.cacertFile,
+		certPemPath: conf.certPemPath,
+		keyPemPath:  conf.keyPemPath,
+		insecure:    conf.insecure,
+		credentials: conf.credentials,
+	}
+
+	settings := d.Get("kibana").([]interface{})
+	if len(settings) == 0 || settings[0] == nil {
+		return k, nil
+	}
+	m := settings[0].(map[string]interface{})
+
+	if m["password"].(string) != "" && m["username"].(string) == "" {
+		return k, errors.New("`kibana.password` requires `kibana.username`")
+	}
+
+	if m["username"].(string) != "" || m["token"].(string) != "" {
+		k.username = m["username"].(string)
+		k.password = m["password"].(string)
+		k.token = m["token"].(string)
+		k.tokenName = m["token_name"].(string)
+		k.credentials = nil
+	}
+	if v := m["cacert_file"].(string); v != "" {
+		k.cacertFile = v
+	}
+	if v := m["client_cert_path"].(string); v != "" {
+		k.certPemPath = v
+		k.keyPemPath = m["client_key_path"].(string)
+	}
+	if v, ok := d.GetOkExists("kibana.0.insecure"); ok {
+		k.insecure = v.(bool)
+	}
+	if headers := m["headers"].(map[string]interface{}); len(headers) > 0 {
+		k.headers = make(map[string]string, len(headers))
+		for name, value := range headers {
+			k.headers[name] = value.(string)
+		}
+	}
+
+	return k, nil
+}
+
+// kibanaProviderConf returns the connection settings of the Kibana client,
+// those of the provider with the Kibana url and settings applied.
+func (conf *ProviderConf) kibanaProviderConf() (*ProviderConf, error) {
+	parsedUrl, err := url.Parse(conf.kibanaUrl)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing kibana url: %+v", err)
+	}
+
+	return &ProviderConf{
+		rawUrl:                   conf.kibanaUrl,
+		urls:                     []string{conf.kibanaUrl},
+		parsedUrl:                parsedUrl,
+		hostOverride:             conf.hostOverride,
+		username:                 conf.kibana.username,
+		password:                 conf.kibana.password,
+		token:                    conf.kibana.token,
+		tokenName:                conf.kibana.tokenName,
+		cacertFile:               conf.kibana.cacertFile,
+		certPemPath:              conf.kibana.certPemPath,
+		keyPemPath:               conf.kibana.keyPemPath,
+		insecure:                 conf.kibana.insecure,
+		signAWSRequests:          conf.signAWSRequests,
+		awsRegion:                conf.awsRegion,
+		awsAssumeRoleArn:         conf.awsAssumeRoleArn,
+		awsAssumeRoleExternalID:  conf.awsAssumeRoleExternalID,
+		awsAssumeRoleSessionName: conf.awsAssumeRoleSessionName,
+		awsWebIdentityTokenFile:  conf.awsWebIdentityTokenFile,
+		awsAssumeRoleDuration:    conf.awsAssumeRoleDuration,
+		awsAssumeRolePolicy:      conf.awsAssumeRolePolicy,
+		awsStsEndpoint:           conf.awsStsEndpoint,
+		awsAccessKeyId:           conf.awsAccessKeyId,
+		awsSecretAccessKey:       conf.awsSecretAccessKey,
+		awsSessionToken:          conf.awsSessionToken,
+		awsSig4Service:           conf.awsSig4Service,
+		serverless:               conf.serverless,
+		awsProfile:               conf.awsProfile,
+		proxyUrl:                 conf.proxyUrl,
+		requestTimeout:           conf.requestTimeout,
+		readOnly:                 conf.readOnly,
+		requestLog:               conf.requestLog,
+		requestLimiter:           conf.requestLimiter,
+		credentials:              conf.kibana.credentials,
+		maxRetries:               conf.maxRetries,
+		retryBackoffMinMs:        conf.retryBackoffMinMs,
+		retryBackoffMaxMs:        conf.retryBackoffMaxMs,
+		retryStatusCodes:         conf.retryStatusCodes,
+	}, nil
+}
+
+// decodeCloudID returns the Elasticsearch and Kibana urls of an Elastic Cloud
+// deployment, the ID is of the form `<name>:<base64 of host$es$kibana>`.
+func decodeCloudID(cloudID string) (string, string, error) {
+	i := strings.LastIndex(cloudID, ":")
+	decoded, err := base64.StdEncoding.DecodeString(cloudID[i+1:])
+	if err != nil {
+		return "", "", fmt.Errorf("error decoding cloud_id: %+v", err)
+	}
+
+	parts := strings.Split(string(decoded), "$")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid cloud_id, expected a host and an Elasticsearch id")
+	}
+
+	// the host may carry a port, which applies to both endpoints
+	host, port := parts[0], ""
+	if j := strings.LastIndex(host, ":"); j >= 0 {
+		host, port = host[:j], host[j:]
+	}
+
+	esUrl := fmt.Sprintf("https://%s.%s%s", parts[1], host, port)
+	var kibanaUrl string
+	if len(parts) > 2 && parts[2] != "" {
+		kibanaUrl = fmt.Sprintf("https://%s.%s%s", parts[2], host, port)
+	}
+	return esUrl, kibanaUrl, nil
+}
+
+// proxy returns the proxy to use for a request, `proxy_url` when it's set,
+// otherwise the one from the environment.
+func (conf *ProviderConf) proxy(req *http.Request) (*url.URL, error) {
+	if conf.proxyUrl != nil {
+		return conf.proxyUrl, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
+
+// serverInfo is the response of the root endpoint of the cluster. The upstream
+// client's PingResult does not expose OpenSearch's `version.distribution`.
+type serverInfo struct {
+	Version struct {
+		Number       string `json:"number"`
+		BuildFlavor  string `json:"build_flavor"`
+		Distribution string `json:"distribution"`
+	} `json:"version"`
+}
+
+func (i serverInfo) flavor() ServerFlavor {
+	if i.Version.Distribution == "opensearch" {
+		return OpenSearch
+	}
+
+	switch i.Version.BuildFlavor {
+	case "default":
+		return Elasticsearch
+	case "oss":
+		return ElasticsearchOpenSource
+	}
+	return Unknown
+}
+
+func pingServerInfo(ctx context.Context, client *elastic7.Client) (*serverInfo, error) {
+	res, err := client.PerformRequest(ctx, elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	info := new(serverInfo)
+	if err := json.Unmarshal(res.Body, info); err != nil {
+		return nil, fmt.Errorf("error unmarshalling server info: %+v: %s", err, res.Body)
+	}
+	return info, nil
+}
+
+// getKibanaClient returns the Kibana client for the provider, creating it on
+// the first call.
 func getKibanaClient(conf *ProviderConf) (interface{}, error) {
+	conf.kibanaClientMu.Lock()
+	defer conf.kibanaClientMu.Unlock()
+
+	if conf.kibanaClient != nil {
+		return conf.kibanaClient, nil
+	}
+
+	client, err := newKibanaClient(conf)
+	if err != nil {
+		return nil, err
+	}
+	conf.kibanaClient = client
+
+	return client, nil
+}
+
+func newKibanaClient(conf *ProviderConf) (interface{}, error) {
 	// use either the provided version of elasticsearch or the version of
-	// elasticsearch determined by pinging the cluster. Base AWS or other auth
-	// off of the same ES config
+	// elasticsearch determined by pinging the cluster. AWS settings are shared
+	// with elasticsearch, the others can be set in the kibana block.
 	esClient, err := getClient(conf)
 	if err != nil {
 		return nil, err
@@ -513,45 +1183,39 @@ func getKibanaClient(conf *ProviderConf) (interface{}, error) {
 
 	switch esClient.(type) {
 	case *elastic7.Client:
+		kconf, err := conf.kibanaProviderConf()
+		if err != nil {
+			return nil, err
+		}
+
 		opts := []elastic7.ClientOptionFunc{
-			elastic7.SetURL(conf.kibanaUrl),
-			elastic7.SetScheme(conf.parsedUrl.Scheme),
+			elastic7.SetURL(kconf.rawUrl),
+			elastic7.SetScheme(kconf.parsedUrl.Scheme),
 			// kibana api does not support sniff/health check
 			elastic7.SetSniff(false),
 			elastic7.SetHealthcheck(false),
 		}
 
-		if conf.parsedUrl.User.Username() != "" {
-			p, _ := conf.parsedUrl.User.Password()
-			opts = append(opts, elastic7.SetBasicAuth(conf.parsedUrl.User.Username(), p))
-		}
-		if conf.username != "" && conf.password != "" {
-			opts = append(opts, elastic7.SetBasicAuth(conf.username, conf.password))
+		if r := newRetrier(kconf); r != nil {
+			opts = append(opts, elastic7.SetRetrier(r), elastic7.SetRetryStatusCodes(kconf.retryStatusCodes...))
 		}
 
 		headers := map[string]string{"kbn-xsrf": "true"}
+		for k, v := range conf.headers {
+			headers[k] = v
+		}
+		for k, v := range conf.kibana.headers {
+			headers[k] = v
+		}
 
-		if m := awsUrlRegexp.FindStringSubmatch(conf.parsedUrl.Hostname()); m != nil && conf.signAWSRequests {
-			log.Printf("[INFO] Using AWS: %+v", m[1])
-			client, err := awsHttpClient(m[1], conf, headers)
-			if err != nil {
-				return nil, err
-			}
-			opts = append(opts, elastic7.SetHttpClient(client), elastic7.SetSniff(false))
-		} else if awsRegion := conf.awsRegion; conf.awsRegion != "" && conf.signAWSRequests {
-			log.Printf("[INFO] Using AWS: %+v", awsRegion)
-			client, err := awsHttpClient(awsRegion, conf, headers)
-			if err != nil {
-				return nil, err
-			}
-			opts = append(opts, elastic7.SetHttpClient(client), elastic7.SetSniff(false))
-		} else if conf.insecure || conf.cacertFile != "" {
-			opts = append(opts, elastic7.SetHttpClient(tlsHttpClient(conf, headers)))
-		} else if conf.token != "" {
-			opts = append(opts, elastic7.SetHttpClient(tokenHttpClient(conf, headers)), elastic7.SetSniff(false))
-		} else {
-			opts = append(opts, elastic7.SetHttpClient(defaultHttpClient(conf, headers)))
+		client, err := newHttpClient(kconf, headers, awsSigningRegion(kconf))
+		if err != nil {
+			return nil, err
+		}
+		if kconf.readOnly {
+			client.Transport = readOnlyTransport{rt: client.Transport}
 		}
+		opts = append(opts, elastic7.SetHttpClient(client))
 
 		return elastic7.NewClient(opts...)
 	case *elastic6.Client:
@@ -561,23 +1225,47 @@ func getKibanaClient(conf *ProviderConf) (interface{}, error) {
 	}
 }
 
-func assumeRoleCredentials(region, roleARN, roleExternalID, roleSessionName, profile string) *awscredentials.Credentials {
-	sessOpts := awsSessionOptions(region)
-	sessOpts.Profile = profile
+func assumeRoleCredentials(region string, conf *ProviderConf) *awscredentials.Credentials {
+	sessOpts := awsSessionOptions(region, conf)
+	sessOpts.Profile = conf.awsProfile
 
 	sess := awssession.Must(awssession.NewSessionWithOptions(sessOpts))
-	stsClient := awssts.New(sess)
+	stsConfig := aws.NewConfig()
+	if conf.awsStsEndpoint != "" {
+		stsConfig = stsConfig.WithEndpoint(conf.awsStsEndpoint)
+	}
+	stsClient := awssts.New(sess, stsConfig)
+
+	if conf.awsWebIdentityTokenFile != "" {
+		sessionName := conf.awsAssumeRoleSessionName
+		if sessionName == "" {
+			sessionName = fmt.Sprintf("terraform-provider-elasticsearch-%d", time.Now().UnixNano())
+		}
+		return awscredentials.NewCredentials(&webIdentityProvider{
+			client:      stsClient,
+			roleArn:     conf.awsAssumeRoleArn,
+			sessionName: sessionName,
+			tokenFile:   conf.awsWebIdentityTokenFile,
+			duration:    conf.awsAssumeRoleDuration,
+			policy:      conf.awsAssumeRolePolicy,
+		})
+	}
+
 	assumeRoleProvider := &awsstscreds.AssumeRoleProvider{
 		Client:          stsClient,
-		RoleARN:         roleARN,
-		RoleSessionName: roleSessionName,
-		ExternalID:      aws.String(roleExternalID),
+		RoleARN:         conf.awsAssumeRoleArn,
+		RoleSessionName: conf.awsAssumeRoleSessionName,
+		ExternalID:      aws.String(conf.awsAssumeRoleExternalID),
+		Duration:        conf.awsAssumeRoleDuration,
+	}
+	if conf.awsAssumeRolePolicy != "" {
+		assumeRoleProvider.Policy = aws.String(conf.awsAssumeRolePolicy)
 	}
 
 	return awscredentials.NewChainCredentials([]awscredentials.Provider{assumeRoleProvider})
 }
 
-func awsSessionOptions(region string) awssession.Options {
+func awsSessionOptions(region string, conf *ProviderConf) awssession.Options {
 	return awssession.Options{
 		Config: aws.Config{
 			Region:   aws.String(region),
@@ -591,14 +1279,14 @@ func awsSessionOptions(region string) awssession.Options {
 			// having zero timeout on the default HTTP client sometimes makes
 			// it fail with Credential error
 			// https://github.com/aws/aws-sdk-go/issues/2914
-			HTTPClient: &http.Client{Timeout: 10 * time.Second},
+			HTTPClient: &http.Client{Timeout: 10 * time.Second, Transport: newTransport(conf, nil)},
 		},
 		SharedConfigState: awssession.SharedConfigEnable,
 	}
 }
 
 func awsSession(region string, conf *ProviderConf) *awssession.Session {
-	sessOpts := awsSessionOptions(region)
+	sessOpts := awsSessionOptions(region, conf)
 
 	// 1. access keys take priority
 	// 2. next is an assume role configuration
@@ -610,143 +1298,20 @@ func awsSession(region string, conf *ProviderConf) *awssession.Session {
 	if conf.awsAccessKeyId != "" {
 		sessOpts.Config.Credentials = awscredentials.NewStaticCredentials(conf.awsAccessKeyId, conf.awsSecretAccessKey, conf.awsSessionToken)
 	} else if conf.awsAssumeRoleArn != "" {
-		if conf.awsAssumeRoleExternalID == "" {
-			conf.awsAssumeRoleExternalID = ""
-		}
-		sessOpts.Config.Credentials = assumeRoleCredentials(region, conf.awsAssumeRoleArn, conf.awsAssumeRoleExternalID, conf.awsAssumeRoleSessionName, conf.awsProfile)
+		sessOpts.Config.Credentials = assumeRoleCredentials(region, conf)
 	} else if conf.awsProfile != "" {
 		sessOpts.Profile = conf.awsProfile
 	}
 
 	// If configured as insecure, turn off SSL verification
 	if conf.insecure {
-		client := &http.Client{Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}}
+		client := &http.Client{Transport: newTransport(conf, &tls.Config{InsecureSkipVerify: true})}
 		sessOpts.Config.HTTPClient = client
 	} else if conf.hostOverride != "" {
 		// Only use `host_override` to set `ServerName` if we're using a secure connection
-		client := &http.Client{Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{ServerName: conf.hostOverride},
-		}}
+		client := &http.Client{Transport: newTransport(conf, &tls.Config{ServerName: conf.hostOverride})}
 		sessOpts.Config.HTTPClient = client
 	}
 
 	return awssession.Must(awssession.NewSessionWithOptions(sessOpts))
 }
-
-func awsHttpClient(region string, conf *ProviderConf, headers map[string]string) (*http.Client, error) {
-	session := awsSession(region, conf)
-	// Call Get() to ensure concurrency safe retrieval of credentials. Since the
-	// client is created in many go routines, this synchronizes it.
-	_, err := session.Config.Credentials.Get()
-	if err != nil {
-		return nil, err
-	}
-	signer := awssigv4.NewSigner(session.Config.Credentials)
-	client, err := aws_signing_client.New(signer, session.Config.HTTPClient, conf.awsSig4Service, region)
-	if err != nil {
-		return nil, err
-	}
-
-	rt := WithHeader(client.Transport)
-	rt.hostOverride = conf.hostOverride
-	for k, v := range headers {
-		rt.Set(k, v)
-	}
-	client.Transport = rt
-
-	return client, nil
-}
-
-func tokenHttpClient(conf *ProviderConf, headers map[string]string) *http.Client {
-	// Setup TLS options
-	tlsConfig := &tls.Config{}
-	if conf.insecure {
-		tlsConfig.InsecureSkipVerify = true
-	} else if conf.hostOverride != "" {
-		tlsConfig.ServerName = conf.hostOverride
-	}
-
-	// Wrapper to inject headers as needed
-	transport := &http.Transport{TLSClientConfig: tlsConfig}
-	rt := WithHeader(transport)
-	rt.hostOverride = conf.hostOverride
-	rt.Set("Authorization", fmt.Sprintf("%s %s", conf.tokenName, conf.token))
-	for k, v := range headers {
-		rt.Set(k, v)
-	}
-
-	client := &http.Client{Transport: rt}
-
-	return client
-}
-
-func tlsHttpClient(conf *ProviderConf, headers map[string]string) *http.Client {
-	// Configure TLS/SSL
-	tlsConfig := &tls.Config{}
-	if conf.certPemPath != "" && conf.keyPemPath != "" {
-		certPem, _, err := readPathOrContent(conf.certPemPath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		keyPem, _, err := readPathOrContent(conf.keyPemPath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		cert, err := tls.X509KeyPair([]byte(certPem), []byte(keyPem))
-		if err != nil {
-			log.Fatal(err)
-		}
-		tlsConfig.Certificates = []tls.Certificate{cert}
-	}
-
-	// If a cacertFile has been specified, use that for cert validation
-	if conf.cacertFile != "" {
-		caCert, _, _ := readPathOrContent(conf.cacertFile)
-
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM([]byte(caCert))
-		tlsConfig.RootCAs = caCertPool
-	}
-
-	// If configured as insecure, turn off SSL verification
-	if conf.insecure {
-		tlsConfig.InsecureSkipVerify = true
-	} else if conf.hostOverride != "" {
-		tlsConfig.ServerName = conf.hostOverride
-	}
-
-	transport := &http.Transport{TLSClientConfig: tlsConfig}
-
-	rt := WithHeader(transport)
-	rt.hostOverride = conf.hostOverride
-	for k, v := range headers {
-		rt.Set(k, v)
-	}
-
-	client := &http.Client{Transport: rt}
-
-	return client
-}
-
-func defaultHttpClient(conf *ProviderConf, headers map[string]string) *http.Client {
-	// Setup TLS options
-	tlsConfig := &tls.Config{}
-	if conf.insecure {
-		tlsConfig.InsecureSkipVerify = true
-	} else if conf.hostOverride != "" {
-		tlsConfig.ServerName = conf.hostOverride
-	}
-
-	// Wrapper to inject headers as needed
-	transport := &http.Transport{TLSClientConfig: tlsConfig}
-	rt := WithHeader(transport)
-	rt.hostOverride = conf.hostOverride
-	for k, v := range headers {
-		rt.Set(k, v)
-	}
-
-	client := &http.Client{Transport: rt}
-	return client
-}
@@ -2,12 +2,37 @@ package es
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	awssigv4 "github.com/aws/aws-sdk-go/aws/signer/v4"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
 )
 
 var testAccProviders map[string]*schema.Provider
@@ -232,6 +257,624 @@ func TestAWSCredsAssumeRole(t *testing.T) {
 	}
 }
 
+// Given:
+// 1. An AWS role ARN and a web identity token file are specified
+// 2. STS and the cluster are served by a local stand-in, like LocalStack
+//
+// This tests that: the role is assumed with the token, duration and policy, and
+// requests to the cluster are signed with the assumed role credentials
+func TestAWSWebIdentityStandIn(t *testing.T) {
+	var stsForm url.Values
+	var authorization string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/sts/" {
+			_ = r.ParseForm()
+			stsForm = r.PostForm
+			w.Header().Set("Content-Type", "text/xml")
+			_, _ = w.Write([]byte(`<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>ASSUMED_ACCESS_KEY</AccessKeyId>
+      <SecretAccessKey>ASSUMED_SECRET</SecretAccessKey>
+      <SessionToken>ASSUMED_TOKEN</SessionToken>
+      <Expiration>2100-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`))
+			return
+		}
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+	}))
+	defer ts.Close()
+
+	tokenFile, err := ioutil.TempFile("", "token")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Remove(tokenFile.Name())
+	_, _ = tokenFile.WriteString("my-oidc-token\n")
+	tokenFile.Close()
+
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":                 ts.URL,
+		"aws_region":          "us-east-1",
+		"aws_assume_role_arn": "arn:aws:iam::123456789012:role/terraform",
+		"aws_assume_role_web_identity_token_file": tokenFile.Name(),
+		"aws_assume_role_duration":                3600,
+		"aws_assume_role_policy":                  `{"Version": "2012-10-17", "Statement": []}`,
+		"aws_sts_endpoint":                        ts.URL + "/sts/",
+		"healthcheck":                             false,
+	})
+	meta, diags := providerConfigure(context.TODO(), d)
+	if diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if _, err := getClient(meta.(*ProviderConf)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if stsForm.Get("Action") != "AssumeRoleWithWebIdentity" || stsForm.Get("WebIdentityToken") != "my-oidc-token" {
+		t.Errorf("expected the role to be assumed with the web identity token, got %v", stsForm)
+	}
+	if stsForm.Get("DurationSeconds") != "3600" || stsForm.Get("Policy") == "" {
+		t.Errorf("expected the duration and policy to be sent, got %v", stsForm)
+	}
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=ASSUMED_ACCESS_KEY/") {
+		t.Errorf("expected requests to be signed with the assumed role credentials, got %q", authorization)
+	}
+}
+
+func TestGetClientIsCached(t *testing.T) {
+	var pings int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/" {
+			atomic.AddInt32(&pings, 1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+	}))
+	defer ts.Close()
+
+	parsedUrl, _ := url.Parse(ts.URL)
+	conf := &ProviderConf{
+		rawUrl:             ts.URL,
+		urls:               []string{ts.URL},
+		parsedUrl:          parsedUrl,
+		healthchecking:     true,
+		pingTimeoutSeconds: 5,
+	}
+
+	var wg sync.WaitGroup
+	clients := make([]interface{}, 10)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client, err := getClient(conf)
+			if err != nil {
+				t.Errorf("err: %s", err)
+			}
+			clients[i] = client
+		}(i)
+	}
+	wg.Wait()
+
+	for _, client := range clients {
+		if client != clients[0] {
+			t.Fatalf("expected the same client to be returned for every call")
+		}
+	}
+	if pings != 1 {
+		t.Errorf("expected the cluster version to be detected once, got %d pings", pings)
+	}
+	if conf.flavor != Elasticsearch {
+		t.Errorf("expected flavor to be detected as Elasticsearch, got %v", conf.flavor)
+	}
+}
+
+func TestEndpointFailover(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+
+	var bodies []string
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			b, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+	}))
+	defer up.Close()
+
+	parsedUrl, _ := url.Parse(down.URL)
+	conf := &ProviderConf{
+		rawUrl:             down.URL,
+		urls:               []string{down.URL, up.URL},
+		urlStrategy:        urlStrategyFailover,
+		parsedUrl:          parsedUrl,
+		pingTimeoutSeconds: 5,
+	}
+
+	esClient, err := getClient(conf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if active := conf.endpoints.Active(); active != up.URL {
+		t.Errorf("expected %s to be active, got %s", up.URL, active)
+	}
+
+	_, err = esClient.(*elastic7.Client).PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "PUT",
+		Path:   "/test",
+		Body:   `{"settings": {}}`,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(bodies) != 1 || bodies[0] != `{"settings": {}}` {
+		t.Errorf("expected the request body to be sent to the failover url, got %v", bodies)
+	}
+}
+
+func TestClientRetriesStatusCodes(t *testing.T) {
+	var requests, postStatus int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(int(atomic.LoadInt32(&postStatus)))
+			return
+		}
+		if r.URL.Path == "/_cluster/health" && atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+	}))
+	defer ts.Close()
+
+	parsedUrl, _ := url.Parse(ts.URL)
+	conf := &ProviderConf{
+		rawUrl:             ts.URL,
+		urls:               []string{ts.URL},
+		parsedUrl:          parsedUrl,
+		pingTimeoutSeconds: 5,
+		maxRetries:         3,
+		retryBackoffMinMs:  1,
+		retryBackoffMaxMs:  10,
+		retryStatusCodes:   defaultRetryStatusCodes,
+	}
+
+	esClient, err := getClient(conf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	_, err = esClient.(*elastic7.Client).PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/_cluster/health",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if requests != 3 {
+		t.Errorf("expected 2 retries, got %d requests", requests)
+	}
+
+	conf.client = nil
+	conf.maxRetries = 1
+	atomic.StoreInt32(&requests, 0)
+	esClient, err = getClient(conf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	_, err = esClient.(*elastic7.Client).PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/_cluster/health",
+	})
+	if !elastic7.IsStatusCode(err, http.StatusServiceUnavailable) {
+		t.Errorf("expected a 503 once retries are exhausted, got %v", err)
+	}
+
+	// POST requests may have been applied behind a 502 or 504, so they're
+	// only retried on a 429 or 503
+	for status, expected := range map[int32]int32{http.StatusBadGateway: 1, http.StatusGatewayTimeout: 1, http.StatusServiceUnavailable: 2} {
+		atomic.StoreInt32(&requests, 0)
+		atomic.StoreInt32(&postStatus, status)
+		_, _ = esClient.(*elastic7.Client).PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_plugins/_alerting/monitors",
+			Body:   `{}`,
+		})
+		if requests != expected {
+			t.Errorf("expected %d POST requests on a %d, got %d", expected, status, requests)
+		}
+	}
+}
+
+func TestClientRetriesStatusCodesV6(t *testing.T) {
+	var requests int32
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/my-index/_doc" {
+			body, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			if atomic.AddInt32(&requests, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": {"number": "6.8.0", "build_flavor": "default"}}`))
+	}))
+	defer ts.Close()
+
+	parsedUrl, _ := url.Parse(ts.URL)
+	conf := &ProviderConf{
+		rawUrl:             ts.URL,
+		urls:               []string{ts.URL},
+		parsedUrl:          parsedUrl,
+		pingTimeoutSeconds: 5,
+		maxRetries:         3,
+		retryBackoffMinMs:  1,
+		retryBackoffMaxMs:  10,
+		retryStatusCodes:   defaultRetryStatusCodes,
+	}
+
+	esClient, err := getClient(conf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	_, err = esClient.(*elastic6.Client).PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		Method: "POST",
+		Path:   "/my-index/_doc",
+		Body:   `{"foo": "bar"}`,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected the 503 to be retried, got %d requests", requests)
+	}
+	if len(bodies) != 2 || bodies[1] != `{"foo": "bar"}` {
+		t.Errorf("expected the body to be sent again, got %v", bodies)
+	}
+}
+
+func TestClientRequestTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+	}))
+	defer ts.Close()
+
+	parsedUrl, _ := url.Parse(ts.URL)
+	conf := &ProviderConf{
+		rawUrl:             ts.URL,
+		urls:               []string{ts.URL},
+		parsedUrl:          parsedUrl,
+		pingTimeoutSeconds: 5,
+		requestTimeout:     100 * time.Millisecond,
+	}
+
+	esClient, err := getClient(conf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	start := time.Now()
+	_, err = esClient.(*elastic7.Client).PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/slow",
+	})
+	if err == nil {
+		t.Fatalf("expected the request to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the request to be aborted after the request timeout, took %s", elapsed)
+	}
+
+	// cancelling the context aborts the request as well
+	conf.requestTimeout = 0
+	conf.client = nil
+	esClient, err = getClient(conf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	_, err = esClient.(*elastic7.Client).PerformRequest(ctx, elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/slow",
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context deadline to be exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the request to be aborted with the context, took %s", elapsed)
+	}
+}
+
+func TestClientUsesProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.Host)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+	}))
+	defer proxy.Close()
+
+	rawUrl := "http://elasticsearch.internal:9200"
+	parsedUrl, _ := url.Parse(rawUrl)
+	proxyUrl, _ := url.Parse(proxy.URL)
+	conf := &ProviderConf{
+		rawUrl:             rawUrl,
+		urls:               []string{rawUrl},
+		parsedUrl:          parsedUrl,
+		pingTimeoutSeconds: 5,
+		proxyUrl:           proxyUrl,
+	}
+
+	if _, err := getClient(conf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(proxied) != 1 || proxied[0] != "elasticsearch.internal:9200" {
+		t.Errorf("expected the version ping to be sent through the proxy, got %v", proxied)
+	}
+}
+
+func TestDecodeCloudID(t *testing.T) {
+	cases := []struct {
+		cloudID   string
+		esUrl     string
+		kibanaUrl string
+		err       bool
+	}{
+		{
+			cloudID:   "my-deployment:" + base64.StdEncoding.EncodeToString([]byte("us-east-1.aws.found.io$abc123$def456")),
+			esUrl:     "https://abc123.us-east-1.aws.found.io",
+			kibanaUrl: "https://def456.us-east-1.aws.found.io",
+		},
+		{
+			cloudID:   "my:deployment:" + base64.StdEncoding.EncodeToString([]byte("europe-west1.gcp.cloud.es.io:9243$abc123$def456")),
+			esUrl:     "https://abc123.europe-west1.gcp.cloud.es.io:9243",
+			kibanaUrl: "https://def456.europe-west1.gcp.cloud.es.io:9243",
+		},
+		{
+			cloudID: base64.StdEncoding.EncodeToString([]byte("us-east-1.aws.found.io$abc123")),
+			esUrl:   "https://abc123.us-east-1.aws.found.io",
+		},
+		{cloudID: "my-deployment:not base64", err: true},
+		{cloudID: "my-deployment:" + base64.StdEncoding.EncodeToString([]byte("us-east-1.aws.found.io")), err: true},
+	}
+
+	for _, tc := range cases {
+		esUrl, kibanaUrl, err := decodeCloudID(tc.cloudID)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", tc.cloudID)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.cloudID, err)
+		}
+		if esUrl != tc.esUrl || kibanaUrl != tc.kibanaUrl {
+			t.Errorf("%s: expected %s and %s, got %s and %s", tc.cloudID, tc.esUrl, tc.kibanaUrl, esUrl, kibanaUrl)
+		}
+	}
+}
+
+func TestProviderConfigureApiKey(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":            "http://127.0.0.1:9200",
+		"api_key_id":     "my-id",
+		"api_key_secret": "my-secret",
+	})
+
+	meta, diags := providerConfigure(context.TODO(), d)
+	if diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	conf := meta.(*ProviderConf)
+	if conf.tokenName != "ApiKey" || conf.token != base64.StdEncoding.EncodeToString([]byte("my-id:my-secret")) {
+		t.Errorf("expected an encoded ApiKey token, got %s %s", conf.tokenName, conf.token)
+	}
+}
+
+func TestProviderConfigureValidatesTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	validCA := writeTestCACert(t, dir)
+	invalid := dir + "/invalid.pem"
+	_ = ioutil.WriteFile(invalid, []byte("not a certificate"), 0600)
+
+	cases := []struct {
+		config   map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{"cacert_file": validCA}, ""},
+		{map[string]interface{}{"cacert_file": invalid}, "does not contain any PEM encoded certificates"},
+		{map[string]interface{}{"cacert_file": dir + "/missing.pem"}, "neither an existing file nor PEM encoded certificates"},
+		{map[string]interface{}{"client_cert_path": validCA}, "must be set together"},
+		{map[string]interface{}{"client_cert_path": validCA, "client_key_path": invalid}, "error loading the client certificate"},
+		{map[string]interface{}{"kibana_url": "http://127.0.0.1:5601", "kibana": []interface{}{map[string]interface{}{"cacert_file": invalid}}}, "invalid `kibana` TLS settings"},
+	}
+
+	for _, tc := range cases {
+		tc.config["url"] = "http://127.0.0.1:9200"
+		d := schema.TestResourceDataRaw(t, Provider().Schema, tc.config)
+
+		_, diags := providerConfigure(context.TODO(), d)
+		if tc.expected == "" && diags.HasError() {
+			t.Errorf("%v: unexpected error: %v", tc.config, diags)
+		}
+		if tc.expected != "" && (!diags.HasError() || !strings.Contains(diags[0].Summary, tc.expected)) {
+			t.Errorf("%v: expected error containing %q, got %v", tc.config, tc.expected, diags)
+		}
+	}
+}
+
+// writeTestCACert writes the certificate of a test TLS server to dir, returning
+// its path.
+func writeTestCACert(t *testing.T, dir string) string {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	path := dir + "/ca.pem"
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return path
+}
+
+func TestKibanaConfFallback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	caCert := writeTestCACert(t, dir)
+
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":           "http://127.0.0.1:9200",
+		"username":      "elastic",
+		"password":      "changeme",
+		"cacert_file":   caCert,
+		"insecure":      true,
+		"host_override": "es.internal",
+		"kibana": []interface{}{
+			map[string]interface{}{
+				"token":    "kibana-token",
+				"insecure": false,
+				"headers":  map[string]interface{}{"X-Tenant": "a"},
+			},
+		},
+	})
+
+	meta, diags := providerConfigure(context.TODO(), d)
+	if diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	k := meta.(*ProviderConf).kibana
+	if k.token != "kibana-token" || k.username != "" || k.password != "" {
+		t.Errorf("expected only the kibana credentials to be used, got %+v", k)
+	}
+	if k.cacertFile != caCert {
+		t.Errorf("expected the CA to fall back to cacert_file, got %s", k.cacertFile)
+	}
+	if k.insecure {
+		t.Errorf("expected insecure to be disabled for kibana")
+	}
+	if k.headers["X-Tenant"] != "a" {
+		t.Errorf("expected kibana headers to be set, got %v", k.headers)
+	}
+	kconf, err := meta.(*ProviderConf).kibanaProviderConf()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if kconf.hostOverride != "es.internal" {
+		t.Errorf("expected host_override to apply to kibana, got %q", kconf.hostOverride)
+	}
+
+	// a kibana password isn't dropped silently without a username
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":      "http://127.0.0.1:9200",
+		"username": "elastic",
+		"password": "changeme",
+		"kibana": []interface{}{
+			map[string]interface{}{"password": "kibana-password"},
+		},
+	})
+	_, diags = providerConfigure(context.TODO(), d)
+	if !diags.HasError() || diags[0].Summary != "`kibana.password` requires `kibana.username`" {
+		t.Errorf("expected an incomplete kibana credentials error, got %v", diags)
+	}
+}
+
+func TestKibanaClientSettings(t *testing.T) {
+	es := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+	}))
+	defer es.Close()
+
+	var kibanaRequest *http.Request
+	kibana := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		kibanaRequest = r
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer kibana.Close()
+
+	parsedUrl, _ := url.Parse(es.URL)
+	conf := &ProviderConf{
+		rawUrl:             es.URL,
+		urls:               []string{es.URL},
+		parsedUrl:          parsedUrl,
+		kibanaUrl:          kibana.URL,
+		pingTimeoutSeconds: 5,
+		username:           "elastic",
+		password:           "changeme",
+		kibana: kibanaConf{
+			username: "kibana",
+			password: "secret",
+			headers:  map[string]string{"X-Tenant": "a"},
+		},
+	}
+
+	kibanaClient, err := getKibanaClient(conf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	_, err = kibanaClient.(*elastic7.Client).PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/api/status",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if user, pass, _ := kibanaRequest.BasicAuth(); user != "kibana" || pass != "secret" {
+		t.Errorf("expected the kibana credentials, got %s:%s", user, pass)
+	}
+	if kibanaRequest.Header.Get("kbn-xsrf") != "true" || kibanaRequest.Header.Get("X-Tenant") != "a" {
+		t.Errorf("expected kibana headers, got %v", kibanaRequest.Header)
+	}
+}
+
+func TestServerInfoFlavor(t *testing.T) {
+	cases := map[string]ServerFlavor{
+		`{"version": {"number": "7.10.2", "build_flavor": "default"}}`:                          Elasticsearch,
+		`{"version": {"number": "7.10.2", "build_flavor": "oss"}}`:                              ElasticsearchOpenSource,
+		`{"version": {"number": "2.4.0", "distribution": "opensearch", "build_flavor": "oss"}}`: OpenSearch,
+		`{"version": {"number": "6.8.0"}}`:                                                      Unknown,
+	}
+
+	for body, expected := range cases {
+		var info serverInfo
+		if err := json.Unmarshal([]byte(body), &info); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if info.flavor() != expected {
+			t.Errorf("%s: expected flavor %v, got %v", body, expected, info.flavor())
+		}
+	}
+}
+
 func getCreds(t *testing.T, region string, config map[string]interface{}) credentials.Value {
 	awsAccessKey := ""
 	awsSecretKey := ""
@@ -261,3 +904,757 @@ func getCreds(t *testing.T, region string, config map[string]interface{}) creden
 	}
 	return creds
 }
+
+func TestClientSendsHeaders(t *testing.T) {
+	var requests []http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Header.Clone())
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+	}))
+	defer ts.Close()
+
+	parsedUrl, _ := url.Parse(ts.URL)
+	conf := &ProviderConf{
+		rawUrl:             ts.URL,
+		urls:               []string{ts.URL},
+		parsedUrl:          parsedUrl,
+		pingTimeoutSeconds: 5,
+		headers:            map[string]string{"X-Tenant": "team-a"},
+	}
+
+	r := &schema.Resource{
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			esClient, err := getClient(meta.(*ProviderConf))
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			_, err = esClient.(*elastic7.Client).PerformRequest(ctx, elastic7.PerformRequestOptions{
+				Method: "GET",
+				Path:   "/my-index",
+			})
+			return diag.FromErr(err)
+		},
+	}
+	withOpaqueID("elasticsearch_index", r)
+
+	d := r.TestResourceData()
+	d.SetId("my-index")
+	if diags := r.ReadContext(context.TODO(), d, conf); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	last := requests[len(requests)-1]
+	if got := last.Get("X-Tenant"); got != "team-a" {
+		t.Errorf("expected X-Tenant header team-a, got %q", got)
+	}
+	if got := last.Get("X-Opaque-Id"); got != "terraform/elasticsearch_index/my-index" {
+		t.Errorf("expected X-Opaque-Id terraform/elasticsearch_index/my-index, got %q", got)
+	}
+
+	// a user supplied X-Opaque-Id takes precedence
+	conf.client = nil
+	conf.headers["X-Opaque-Id"] = "custom"
+	if diags := r.ReadContext(context.TODO(), d, conf); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if got := requests[len(requests)-1].Get("X-Opaque-Id"); got != "custom" {
+		t.Errorf("expected X-Opaque-Id custom, got %q", got)
+	}
+}
+
+func TestClientCombinesTLSAndAuth(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "terraform"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var authorization string
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	ts.StartTLS()
+	defer ts.Close()
+
+	parsedUrl, _ := url.Parse(ts.URL)
+	conf := &ProviderConf{
+		rawUrl:             ts.URL,
+		urls:               []string{ts.URL},
+		parsedUrl:          parsedUrl,
+		pingTimeoutSeconds: 5,
+		cacertFile:         string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})),
+		certPemPath:        string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer})),
+		keyPemPath:         string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})),
+		token:              "my-api-key",
+		tokenName:          "ApiKey",
+	}
+
+	if _, err := getClient(conf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if authorization != "ApiKey my-api-key" {
+		t.Errorf("expected the api key to be sent with the client certificate, got %q", authorization)
+	}
+
+	conf.client = nil
+	conf.esVersion = ""
+	conf.token = ""
+	conf.username = "elastic"
+	conf.password = "changeme"
+	if _, err := getClient(conf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if authorization != "Basic "+base64.StdEncoding.EncodeToString([]byte("elastic:changeme")) {
+		t.Errorf("expected basic auth to be sent with the client certificate, got %q", authorization)
+	}
+}
+
+func TestClientReadOnly(t *testing.T) {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+	}))
+	defer ts.Close()
+
+	parsedUrl, _ := url.Parse(ts.URL)
+	conf := &ProviderConf{
+		rawUrl:             ts.URL,
+		urls:               []string{ts.URL},
+		parsedUrl:          parsedUrl,
+		pingTimeoutSeconds: 5,
+		maxRetries:         3,
+		readOnly:           true,
+	}
+
+	esClient, err := getClient(conf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	client := esClient.(*elastic7.Client)
+
+	for _, method := range []string{"GET", "HEAD"} {
+		if _, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{Method: method, Path: "/my-index"}); err != nil {
+			t.Errorf("expected %s to be allowed, got %s", method, err)
+		}
+	}
+	for _, path := range []string{"/my-index/_search", "/_search", "/.opendistro-alerting-config/_count", "/_ingest/pipeline/my-pipeline/_simulate", "/my-index/_explain/1", "/_index_template/_simulate_index/my-index", "/_security/user/_has_privileges"} {
+		if _, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{Method: "POST", Path: path}); err != nil {
+			t.Errorf("expected POST %s to be allowed, got %s", path, err)
+		}
+	}
+
+	count := len(requests)
+	ctx := context.WithValue(context.TODO(), opaqueIDKey{}, "terraform/elasticsearch_index/my-index")
+	for _, method := range []string{"PUT", "POST", "DELETE"} {
+		_, err := client.PerformRequest(ctx, elastic7.PerformRequestOptions{Method: method, Path: "/my-index"})
+		expected := fmt.Sprintf("provider is read_only, refusing to send %s /my-index for elasticsearch_index/my-index", method)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	}
+
+	// writes to objects named like a read only endpoint are rejected
+	for _, path := range []string{"/_scripts/_search", "/my-index/_doc/_search", "/my-index/_update/_count", "/_search/my-index", "/_security/role/_has_privileges"} {
+		_, err := client.PerformRequest(ctx, elastic7.PerformRequestOptions{Method: "POST", Path: path})
+		if err == nil || !strings.Contains(err.Error(), "provider is read_only") {
+			t.Errorf("expected POST %s to be rejected, got %v", path, err)
+		}
+	}
+	if len(requests) != count {
+		t.Errorf("expected no mutating requests to be sent, got %v", requests[count:])
+	}
+}
+
+func TestClientRequestLog(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/_license" {
+			_, _ = w.Write([]byte(`{"license": {"uid": "1234", "signature": "secret"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "request-log")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	logger, err := newRequestLogger(dir+"/requests.log", []string{"api_key"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	parsedUrl, _ := url.Parse(ts.URL)
+	conf := &ProviderConf{
+		rawUrl:             ts.URL,
+		urls:               []string{ts.URL},
+		parsedUrl:          parsedUrl,
+		pingTimeoutSeconds: 5,
+		username:           "elastic",
+		password:           "changeme",
+		requestLog:         logger,
+	}
+
+	esClient, err := getClient(conf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	ctx := context.WithValue(context.TODO(), opaqueIDKey{}, "terraform/elasticsearch_xpack_user/jane")
+	_, err = esClient.(*elastic7.Client).PerformRequest(ctx, elastic7.PerformRequestOptions{
+		Method: "PUT",
+		Path:   "/_security/user/jane",
+		Body:   map[string]interface{}{"password": "s3cret", "metadata": map[string]interface{}{"api_key": "abc", "team": "a"}},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	_, err = esClient.(*elastic7.Client).PerformRequest(context.TODO(), elastic7.PerformRequestOptions{Method: "GET", Path: "/_license"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	_, err = esClient.(*elastic7.Client).PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "PUT",
+		Path:   "/_snapshot/backups",
+		Body:   `{"type": "s3", "settings": {"bucket": "backups", "access_key": "AKIAEXAMPLE", "secret_key": "wJalrXUtnFEMI"}}`,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	contents, err := ioutil.ReadFile(dir + "/requests.log")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, secret := range []string{"s3cret", "abc", "changeme", "Basic ", `"secret"`, "AKIAEXAMPLE", "wJalrXUtnFEMI"} {
+		if strings.Contains(string(contents), secret) {
+			t.Errorf("expected %q to be redacted from the request log:\n%s", secret, contents)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 requests to be logged, got %d:\n%s", len(lines), contents)
+	}
+	if !strings.Contains(lines[3], `"bucket":"backups"`) {
+		t.Errorf("expected the repository settings that aren't sensitive to be logged:\n%s", lines[3])
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if entry["method"] != "PUT" || entry["path"] != "/_security/user/jane" || entry["status"] != float64(200) || entry["resource"] != "elasticsearch_xpack_user/jane" {
+		t.Errorf("unexpected request log entry: %v", entry)
+	}
+	if _, ok := entry["duration_ms"]; !ok {
+		t.Errorf("expected the duration to be logged: %v", entry)
+	}
+	if team := entry["request_body"].(map[string]interface{})["metadata"].(map[string]interface{})["team"]; team != "a" {
+		t.Errorf("expected fields that aren't sensitive to be logged, got %v", team)
+	}
+
+	// the session token of AWS signed requests is redacted
+	client := &http.Client{Transport: &awsSigningTransport{
+		rt:      requestLogTransport{rt: http.DefaultTransport, logger: logger},
+		signer:  awssigv4.NewSigner(credentials.NewStaticCredentials("ACCESS_KEY", "SECRET", "SESSION_TOKEN")),
+		service: "es",
+		region:  "us-east-1",
+	}}
+	res, err := client.Get(ts.URL + "/_cluster/health")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	res.Body.Close()
+
+	contents, err = ioutil.ReadFile(dir + "/requests.log")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	lines = strings.Split(strings.TrimSpace(string(contents)), "\n")
+	if strings.Contains(lines[len(lines)-1], "SESSION_TOKEN") || !strings.Contains(lines[len(lines)-1], `"X-Amz-Security-Token":"REDACTED"`) {
+		t.Errorf("expected the session token to be redacted from the request log:\n%s", lines[len(lines)-1])
+	}
+}
+
+func TestAWSServerless(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url": "https://abc123.us-east-1.aoss.amazonaws.com",
+	})
+	meta, diags := providerConfigure(context.TODO(), d)
+	if diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	conf := meta.(*ProviderConf)
+	if !conf.serverless || conf.awsSig4Service != "aoss" {
+		t.Errorf("expected an aoss endpoint to be detected as serverless, got %v %s", conf.serverless, conf.awsSig4Service)
+	}
+	if region := awsSigningRegion(conf); region != "us-east-1" {
+		t.Errorf("expected the region to be us-east-1, got %s", region)
+	}
+
+	var header http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &awsSigningTransport{
+		rt:      http.DefaultTransport,
+		signer:  awssigv4.NewSigner(credentials.NewStaticCredentials("ACCESS_KEY", "SECRET", "")),
+		service: "aoss",
+		region:  "us-east-1",
+	}}
+	res, err := client.Post(ts.URL+"/my-index/_doc", "application/json", strings.NewReader(`{"foo": "bar"}`))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	res.Body.Close()
+
+	sum := sha256.Sum256([]byte(`{"foo": "bar"}`))
+	if got := header.Get("X-Amz-Content-Sha256"); got != hex.EncodeToString(sum[:]) {
+		t.Errorf("expected the payload hash header to be set, got %q", got)
+	}
+	if !strings.Contains(header.Get("Authorization"), "/us-east-1/aoss/aws4_request") {
+		t.Errorf("expected the request to be signed for aoss, got %q", header.Get("Authorization"))
+	}
+}
+
+func TestClientCredentialsFromFiles(t *testing.T) {
+	accepted := "Bearer first"
+	var rejected int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != accepted {
+			atomic.AddInt32(&rejected, 1)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := dir + "/token"
+	_ = ioutil.WriteFile(tokenFile, []byte("first\n"), 0600)
+
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":         ts.URL,
+		"token_file":  tokenFile,
+		"token_name":  "Bearer",
+		"healthcheck": false,
+	})
+	meta, diags := providerConfigure(context.TODO(), d)
+	if diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// the token is rotated keeping the modification time, the rejected request
+	// is retried with the new token
+	info, _ := os.Stat(tokenFile)
+	_ = ioutil.WriteFile(tokenFile, []byte("second\n"), 0600)
+	_ = os.Chtimes(tokenFile, info.ModTime(), info.ModTime())
+	accepted = "Bearer second"
+	_, err = esClient.(*elastic7.Client).PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "PUT",
+		Path:   "/my-index",
+		Body:   `{"settings": {}}`,
+	})
+	if err != nil {
+		t.Fatalf("expected the request to succeed with the rotated token, got %s", err)
+	}
+	if rejected != 1 {
+		t.Errorf("expected one rejected request, got %d", rejected)
+	}
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":        ts.URL,
+		"token_file": dir + "/missing",
+	})
+	if _, diags := providerConfigure(context.TODO(), d); !diags.HasError() || !strings.Contains(diags[0].Summary, "file does not exist") {
+		t.Errorf("expected a missing token file to fail, got %v", diags)
+	}
+}
+
+func TestClientCredentialsCommand(t *testing.T) {
+	var authorization string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+	}))
+	defer ts.Close()
+
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":                 ts.URL,
+		"username":            "elastic",
+		"credentials_command": []interface{}{"sh", "-c", `echo '{"password": "from-vault"}'`},
+	})
+	meta, diags := providerConfigure(context.TODO(), d)
+	if diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if _, err := getClient(meta.(*ProviderConf)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if expected := "Basic " + base64.StdEncoding.EncodeToString([]byte("elastic:from-vault")); authorization != expected {
+		t.Errorf("expected %q, got %q", expected, authorization)
+	}
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":                 ts.URL,
+		"credentials_command": []interface{}{"sh", "-c", "echo 'vault is sealed' >&2; exit 1"},
+	})
+	if _, diags := providerConfigure(context.TODO(), d); !diags.HasError() || !strings.Contains(diags[0].Summary, "vault is sealed") {
+		t.Errorf("expected the command error to be reported, got %v", diags)
+	}
+}
+
+func TestCurrentIdentity(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, _ := r.BasicAuth(); username != "elastic" || password != "changeme" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/_security/_authenticate":
+			_, _ = w.Write([]byte(`{"username": "elastic", "roles": ["superuser"], "authentication_realm": {"name": "reserved", "type": "reserved"}, "authentication_type": "realm"}`))
+		case "/_plugins/_security/authinfo", "/_opendistro/_security/authinfo":
+			_, _ = w.Write([]byte(`{"user_name": "elastic", "roles": ["all_access"], "backend_roles": ["admin"]}`))
+		case "/_xpack/security/_authenticate":
+			_, _ = w.Write([]byte(`{"username": "elastic", "roles": ["superuser"], "authentication_realm": {"name": "reserved", "type": "reserved"}}`))
+		default:
+			switch r.Header.Get("X-Distribution") {
+			case "opensearch":
+				_, _ = w.Write([]byte(`{"version": {"number": "2.4.0", "distribution": "opensearch"}}`))
+				return
+			case "opendistro":
+				_, _ = w.Write([]byte(`{"version": {"number": "6.8.0", "build_flavor": "oss"}}`))
+				return
+			case "xpack6":
+				_, _ = w.Write([]byte(`{"version": {"number": "6.8.0", "build_flavor": "default"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+		}
+	}))
+	defer ts.Close()
+
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":                  ts.URL,
+		"username":             "elastic",
+		"password":             "changeme",
+		"validate_credentials": true,
+	})
+	meta, diags := providerConfigure(context.TODO(), d)
+	if diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	r := dataSourceElasticsearchCurrentIdentity()
+	data := r.TestResourceData()
+	if diags := r.ReadContext(context.TODO(), data, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if data.Get("username") != "elastic" || data.Get("realm") != "reserved" || data.Get("authentication_type") != "realm" || data.Get("roles.0") != "superuser" {
+		t.Errorf("unexpected identity: %v", data.State())
+	}
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":      ts.URL,
+		"username": "elastic",
+		"password": "changeme",
+		"headers":  map[string]interface{}{"X-Distribution": "opensearch"},
+	})
+	meta, diags = providerConfigure(context.TODO(), d)
+	if diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	id, err := currentIdentity(context.TODO(), meta.(*ProviderConf))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if id.Username != "elastic" || len(id.Roles) != 1 || id.Roles[0] != "all_access" {
+		t.Errorf("unexpected OpenSearch identity: %+v", id)
+	}
+
+	// Elasticsearch 6 uses the security plugin on Open Distro, X-Pack otherwise
+	expected := map[string]string{"opendistro": "all_access", "xpack6": "superuser"}
+	for distribution, role := range expected {
+		d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+			"url":      ts.URL,
+			"username": "elastic",
+			"password": "changeme",
+			"headers":  map[string]interface{}{"X-Distribution": distribution},
+		})
+		meta, diags = providerConfigure(context.TODO(), d)
+		if diags.HasError() {
+			t.Fatalf("err: %v", diags)
+		}
+		id, err := currentIdentity(context.TODO(), meta.(*ProviderConf))
+		if err != nil {
+			t.Fatalf("%s: err: %s", distribution, err)
+		}
+		if id.Username != "elastic" || len(id.Roles) != 1 || id.Roles[0] != role {
+			t.Errorf("%s: unexpected identity: %+v", distribution, id)
+		}
+	}
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":                   ts.URL,
+		"username":              "elastic",
+		"password":              "wrong",
+		"elasticsearch_version": "7.10.2",
+		"healthcheck":           false,
+		"validate_credentials":  true,
+	})
+	_, diags = providerConfigure(context.TODO(), d)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "HTTP 401 Unauthorized") {
+		t.Errorf("expected rejected credentials to fail, got %v", diags)
+	}
+}
+
+func TestClientLimitsConcurrentRequests(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight, writesInFlight, maxWritesInFlight int
+	track := func(delta int, write bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		inFlight += delta
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		if write {
+			writesInFlight += delta
+			if writesInFlight > maxWritesInFlight {
+				maxWritesInFlight = writesInFlight
+			}
+		}
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			track(1, r.Method == http.MethodPut)
+			time.Sleep(20 * time.Millisecond)
+			track(-1, r.Method == http.MethodPut)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+	}))
+	defer ts.Close()
+
+	parsedUrl, _ := url.Parse(ts.URL)
+	conf := &ProviderConf{
+		rawUrl:             ts.URL,
+		urls:               []string{ts.URL},
+		parsedUrl:          parsedUrl,
+		pingTimeoutSeconds: 5,
+		requestLimiter:     newRequestLimiter(3, 1),
+	}
+	esClient, err := getClient(conf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		method := "GET"
+		if i%2 == 0 {
+			method = "PUT"
+		}
+		wg.Add(1)
+		go func(method string) {
+			defer wg.Done()
+			_, err := esClient.(*elastic7.Client).PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+				Method: method,
+				Path:   "/my-index",
+			})
+			if err != nil {
+				t.Errorf("err: %s", err)
+			}
+		}(method)
+	}
+	wg.Wait()
+
+	if maxInFlight > 3 {
+		t.Errorf("expected at most 3 concurrent requests, got %d", maxInFlight)
+	}
+	if maxWritesInFlight != 1 {
+		t.Errorf("expected at most 1 concurrent write request, got %d", maxWritesInFlight)
+	}
+
+	// waiting for a slot is aborted when the request is cancelled
+	release, _ := conf.requestLimiter.acquire(httptest.NewRequest("PUT", "/my-index", nil))
+	defer release()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = esClient.(*elastic7.Client).PerformRequest(ctx, elastic7.PerformRequestOptions{Method: "PUT", Path: "/my-index"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the request to time out waiting for a slot, got %v", err)
+	}
+}
+
+func TestClientLimitReleasedOnRetry(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/my-index" && atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error": "too many requests"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": {"number": "7.10.2", "build_flavor": "default"}}`))
+	}))
+	defer ts.Close()
+
+	parsedUrl, _ := url.Parse(ts.URL)
+	conf := &ProviderConf{
+		rawUrl:             ts.URL,
+		urls:               []string{ts.URL},
+		parsedUrl:          parsedUrl,
+		pingTimeoutSeconds: 5,
+		maxRetries:         3,
+		retryBackoffMinMs:  1,
+		retryBackoffMaxMs:  10,
+		retryStatusCodes:   defaultRetryStatusCodes,
+		requestLimiter:     newRequestLimiter(1, 0),
+	}
+	esClient, err := getClient(conf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// the slot of the retried 429 is released, so neither the retry nor the
+	// next request wait for it
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_, err = esClient.(*elastic7.Client).PerformRequest(ctx, elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/my-index",
+		})
+		cancel()
+		if err != nil {
+			t.Fatalf("request %d: err: %s", i, err)
+		}
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+}
+
+func TestClientSurfacesWarnings(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/_template/legacy" {
+			w.Header().Add("Warning", `299 Elasticsearch-7.17.0-bee86328705acaa9a6daede7140defd4d9ec56bd "Legacy index templates are deprecated in favor of composable templates." "Mon, 01 Jan 2024 00:00:00 GMT"`)
+			w.Header().Add("Warning", `299 Elasticsearch-7.17.0-bee86328705acaa9a6daede7140defd4d9ec56bd "[index.lifecycle.rollover_alias] setting was \"deprecated\""`)
+		}
+		_, _ = w.Write([]byte(`{"version": {"number": "7.17.0", "build_flavor": "default"}}`))
+	}))
+	defer ts.Close()
+
+	parsedUrl, _ := url.Parse(ts.URL)
+	conf := &ProviderConf{
+		rawUrl:             ts.URL,
+		urls:               []string{ts.URL},
+		parsedUrl:          parsedUrl,
+		pingTimeoutSeconds: 5,
+	}
+
+	r := &schema.Resource{
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			esClient, err := getClient(meta.(*ProviderConf))
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			// the warnings of repeated requests are only reported once
+			for i := 0; i < 2; i++ {
+				_, err = esClient.(*elastic7.Client).PerformRequest(ctx, elastic7.PerformRequestOptions{
+					Method: "GET",
+					Path:   "/_template/legacy",
+				})
+				if err != nil {
+					return diag.FromErr(err)
+				}
+			}
+			return nil
+		},
+	}
+	withWarnings(r)
+
+	diags := r.ReadContext(context.TODO(), r.TestResourceData(), conf)
+	if diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	expected := []string{
+		"Legacy index templates are deprecated in favor of composable templates.",
+		`[index.lifecycle.rollover_alias] setting was "deprecated"`,
+	}
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d warnings, got %v", len(expected), diags)
+	}
+	for i, d := range diags {
+		if d.Severity != diag.Warning || d.Detail != expected[i] {
+			t.Errorf("expected warning %q, got %+v", expected[i], d)
+		}
+	}
+
+	// requests made outside of a resource are sent as usual
+	esClient, err := getClient(conf)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, err = esClient.(*elastic7.Client).PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/_template/legacy",
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestParseWarningHeader(t *testing.T) {
+	cases := map[string]string{
+		`299 Elasticsearch-8.0.0-abc "[types removal] Specifying types is deprecated." "Mon, 01 Jan 2024 00:00:00 GMT"`: "[types removal] Specifying types is deprecated.",
+		`299 Kibana "escaped \\ backslash"`: `escaped \ backslash`,
+		`not a warning`:                     "not a warning",
+		`299 - "unterminated`:               `299 - "unterminated`,
+	}
+	for header, expected := range cases {
+		if got := parseWarningHeader(header); got != expected {
+			t.Errorf("parseWarningHeader(%q): expected %q, got %q", header, expected, got)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package es
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRedactedFields are always redacted from the request log, in bodies
+// as well as headers, e.g. the keys of snapshot repository settings and the
+// client secret of OIDC realms. The log sits below the authentication
+// transports, so the headers they set, including the session token of AWS
+// signed requests, are redacted too.
+var defaultRedactedFields = []string{
+	"password",
+	"password_hash",
+	"license",
+	"authorization",
+	"x-amz-security-token",
+	"access_key",
+	"secret_key",
+	"session_token",
+	"client_secret",
+	"token",
+}
+
+const redacted = "REDACTED"
+
+// requestLogger writes one JSON line per request to the cluster, with the
+// values of sensitive fields and headers redacted.
+type requestLogger struct {
+	mu     sync.Mutex
+	file   *os.File
+	redact map[string]bool
+}
+
+type requestLogEntry struct {
+	Time         time.Time         `json:"time"`
+	Method       string            `json:"method"`
+	Host         string            `json:"host"`
+	Path         string            `json:"path"`
+	Status       int               `json:"status,omitempty"`
+	DurationMs   int64             `json:"duration_ms"`
+	Resource     string            `json:"resource,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	RequestBody  interface{}       `json:"request_body,omitempty"`
+	ResponseBody interface{}       `json:"response_body,omitempty"`
+}
+
+func newRequestLogger(path string, redactFields []string) (*requestLogger, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening `request_log_file`: %+v", err)
+	}
+
+	redact := map[string]bool{}
+	for _, field := range append(defaultRedactedFields, redactFields...) {
+		redact[strings.ToLower(field)] = true
+	}
+	return &requestLogger{file: file, redact: redact}, nil
+}
+
+func (l *requestLogger) write(entry requestLogEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.file.Write(append(line, '\n'))
+}
+
+// redactBody returns the body with the values of redacted fields replaced, as
+// JSON so it is embedded in the log line, or a placeholder for bodies which
+// aren't JSON and can't be redacted.
+func (l *requestLogger) redactBody(body []byte) interface{} {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	// bulk and multi search bodies are newline delimited JSON
+	var docs []interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 64*1024), len(body)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var doc interface{}
+		if err := json.Unmarshal(line, &doc); err != nil {
+			return fmt.Sprintf("<%d bytes>", len(body))
+		}
+		docs = append(docs, l.redactValue(doc))
+	}
+
+	if len(docs) == 1 {
+		return docs[0]
+	}
+	return docs
+}
+
+func (l *requestLogger) redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, value := range v {
+			if l.redact[strings.ToLower(k)] {
+				v[k] = redacted
+			} else {
+				v[k] = l.redactValue(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = l.redactValue(value)
+		}
+	}
+	return v
+}
+
+func (l *requestLogger) redactHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for k := range header {
+		if l.redact[strings.ToLower(k)] {
+			headers[k] = redacted
+		} else {
+			headers[k] = header.Get(k)
+		}
+	}
+	return headers
+}
+
+// requestLogTransport logs the requests sent on the wire, after headers and
+// authentication were added and per retry or failover attempt.
+type requestLogTransport struct {
+	rt     http.RoundTripper
+	logger *requestLogger
+}
+
+func (t requestLogTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := requestLogEntry{
+		Time:    time.Now().UTC(),
+		Method:  req.Method,
+		Host:    req.URL.Host,
+		Path:    req.URL.Path,
+		Headers: t.logger.redactHeaders(req.Header),
+	}
+	entry.Resource, _ = req.Context().Value(opaqueIDKey{}).(string)
+	entry.Resource = strings.TrimPrefix(entry.Resource, "terraform/")
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		entry.RequestBody = t.logger.redactBody(body)
+	}
+
+	res, err := t.rt.RoundTrip(req)
+	entry.DurationMs = time.Since(entry.Time).Milliseconds()
+	if err != nil {
+		entry.Error = err.Error()
+		t.logger.write(entry)
+		return res, err
+	}
+
+	entry.Status = res.StatusCode
+	if res.Body != nil {
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		res.Body = ioutil.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		entry.ResponseBody = t.logger.redactBody(body)
+	}
+	t.logger.write(entry)
+
+	return res, nil
+}
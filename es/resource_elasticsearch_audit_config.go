@@ -9,7 +9,7 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -178,76 +178,65 @@ var auditConfigSchema = map[string]*schema.Schema{
 
 func resourceOpenSearchAuditConfig() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchAuditConfigCreate,
-		Read:   resourceElasticsearchAuditConfigRead,
-		Update: resourceElasticsearchAuditConfigUpdate,
-		Delete: resourceElasticsearchAuditConfigDelete,
-		Schema: auditConfigSchema,
+		CreateContext: resourceElasticsearchAuditConfigCreate,
+		ReadContext:   resourceElasticsearchAuditConfigRead,
+		UpdateContext: resourceElasticsearchAuditConfigUpdate,
+		DeleteContext: resourceElasticsearchAuditConfigDelete,
+		Schema:        auditConfigSchema,
+		CustomizeDiff: capabilityCustomizeDiff("elasticsearch_opensearch_audit_config"),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchAuditConfigCheckVersion(meta interface{}) error {
-	providerConf := meta.(*ProviderConf)
-	if _, err := getClient(providerConf); err != nil {
-		return err
+func resourceElasticsearchAuditConfigCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if err := checkCapability(m, "elasticsearch_opensearch_audit_config"); err != nil {
+		return diag.FromErr(err)
 	}
 
-	elasticVersion, err := version.NewVersion(providerConf.esVersion)
-	if err != nil {
-		return err
-	}
-
-	if providerConf.flavor != Unknown && elasticVersion.Segments()[0] != 1 {
-		return fmt.Errorf("audit config only available from OpenSearch >= 1.0, got version %s", elasticVersion.String())
-	}
-
-	return nil
-}
-
-func resourceElasticsearchAuditConfigCreate(d *schema.ResourceData, m interface{}) error {
-	if err := resourceElasticsearchAuditConfigCheckVersion(m); err != nil {
-		return err
-	}
-
-	if _, err := resourceElasticsearchPutAuditConfig(d, m); err != nil {
-		return err
+	if _, err := resourceElasticsearchPutAuditConfig(ctx, d, m); err != nil {
+		return diag.FromErr(err)
 	}
 
 	d.SetId("audit_config")
-	return resourceElasticsearchAuditConfigRead(d, m)
+	return resourceElasticsearchAuditConfigRead(ctx, d, m)
 }
 
-func resourceElasticsearchAuditConfigRead(d *schema.ResourceData, m interface{}) error {
-	if err := resourceElasticsearchAuditConfigCheckVersion(m); err != nil {
-		return err
+func resourceElasticsearchAuditConfigRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if err := checkCapability(m, "elasticsearch_opensearch_audit_config"); err != nil {
+		return diag.FromErr(err)
 	}
 
-	res, err := resourceElasticsearchGetAuditConfig(m)
+	res, err := resourceElasticsearchGetAuditConfig(ctx, m)
 	if err != nil {
 		if elastic7.IsNotFound(err) {
 			log.Printf("[WARN] audit config (%s) not found, removing from state", d.Id())
 			d.SetId("")
 			return nil
 		}
-		return err
+		return diag.FromErr(err)
 	}
 
 	err = d.Set("enabled", res.Config.Enabled)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	err = d.Set("audit", flattenAudit(res.Config.Audit))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	err = d.Set("compliance", flattenCompliance(res.Config.Compliance))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	return nil
@@ -296,30 +285,35 @@ func flattenAudit(aud auditConfig_audit) []map[string]interface{} {
 	}}
 }
 
-func resourceElasticsearchAuditConfigUpdate(d *schema.ResourceData, m interface{}) error {
-	if err := resourceElasticsearchAuditConfigCheckVersion(m); err != nil {
-		return err
+func resourceElasticsearchAuditConfigUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if err := checkCapability(m, "elasticsearch_opensearch_audit_config"); err != nil {
+		return diag.FromErr(err)
 	}
 
-	if _, err := resourceElasticsearchPutAuditConfig(d, m); err != nil {
-		return err
+	if _, err := resourceElasticsearchPutAuditConfig(ctx, d, m); err != nil {
+		return diag.FromErr(err)
 	}
 
-	return resourceElasticsearchAuditConfigRead(d, m)
+	return resourceElasticsearchAuditConfigRead(ctx, d, m)
 }
 
-func resourceElasticsearchAuditConfigDelete(d *schema.ResourceData, m interface{}) error {
-	if err := resourceElasticsearchAuditConfigCheckVersion(m); err != nil {
-		return err
+func resourceElasticsearchAuditConfigDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if err := checkCapability(m, "elasticsearch_opensearch_audit_config"); err != nil {
+		return diag.FromErr(err)
 	}
 
 	return nil
 }
 
-func resourceElasticsearchGetAuditConfig(m interface{}) (getAuditConfigResponse, error) {
+func resourceElasticsearchGetAuditConfig(ctx context.Context, m interface{}) (getAuditConfigResponse, error) {
 	var err error
 	audit := new(getAuditConfigResponse)
 
+	path, err := pluginPath(m, "_security/api/audit", nil)
+	if err != nil {
+		return *audit, fmt.Errorf("error building URL path for audit config: %+v", err)
+	}
+
 	var body json.RawMessage
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
@@ -328,9 +322,9 @@ func resourceElasticsearchGetAuditConfig(m interface{}) (getAuditConfigResponse,
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "GET",
-			Path:   "/_plugins/_security/api/audit",
+			Path:   path,
 		})
 		if err != nil {
 			return *audit, err
@@ -417,7 +411,7 @@ func expandReadWatchedFields(fields []interface{}) map[string][]string {
 	return result
 }
 
-func resourceElasticsearchPutAuditConfig(d *schema.ResourceData, m interface{}) (*putAuditConfigResponse, error) {
+func resourceElasticsearchPutAuditConfig(ctx context.Context, d *schema.ResourceData, m interface{}) (*putAuditConfigResponse, error) {
 	response := new(putAuditConfigResponse)
 	auditConfig := auditConfig{
 		Enabled:    d.Get("enabled").(bool),
@@ -430,6 +424,11 @@ func resourceElasticsearchPutAuditConfig(d *schema.ResourceData, m interface{})
 		return response, fmt.Errorf("body Error : %s", auditConfigJSON)
 	}
 
+	path, err := pluginPath(m, "_security/api/audit/config", nil)
+	if err != nil {
+		return response, fmt.Errorf("error building URL path for audit config: %+v", err)
+	}
+
 	var body json.RawMessage
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
@@ -439,9 +438,9 @@ func resourceElasticsearchPutAuditConfig(d *schema.ResourceData, m interface{})
 	case *elastic7.Client:
 		var res *elastic7.Response
 		log.Printf("[INFO] put audit config: %+v", auditConfig)
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method:           "PUT",
-			Path:             "/_plugins/_security/api/audit/config",
+			Path:             path,
 			Body:             string(auditConfigJSON),
 			RetryStatusCodes: []int{http.StatusInternalServerError},
 			Retrier: elastic7.NewBackoffRetrier(
@@ -166,7 +166,7 @@ func testCheckElasticSearchOpenSearchSecurityAuditConfigExists(name string) reso
 			}
 			switch esClient.(type) {
 			case *elastic7.Client:
-				_, err = resourceElasticsearchGetAuditConfig(meta.(*ProviderConf))
+				_, err = resourceElasticsearchGetAuditConfig(context.TODO(), meta.(*ProviderConf))
 			default:
 			}
 
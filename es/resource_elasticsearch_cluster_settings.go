@@ -10,7 +10,9 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
@@ -71,11 +73,12 @@ var (
 
 func resourceElasticsearchClusterSettings() *schema.Resource {
 	return &schema.Resource{
-		Description: "Manages a cluster's (persistent) settings.",
-		Create:      resourceElasticsearchClusterSettingsCreate,
-		Read:        resourceElasticsearchClusterSettingsRead,
-		Update:      resourceElasticsearchClusterSettingsUpdate,
-		Delete:      resourceElasticsearchClusterSettingsDelete,
+		Description:   "Manages a cluster's (persistent) settings.",
+		CreateContext: resourceElasticsearchClusterSettingsCreate,
+		ReadContext:   resourceElasticsearchClusterSettingsRead,
+		UpdateContext: resourceElasticsearchClusterSettingsUpdate,
+		DeleteContext: resourceElasticsearchClusterSettingsDelete,
+		CustomizeDiff: serverlessCustomizeDiff("elasticsearch_cluster_settings"),
 		Schema: map[string]*schema.Schema{
 			"cluster_max_shards_per_node": {
 				Type:        schema.TypeInt,
@@ -277,19 +280,25 @@ func resourceElasticsearchClusterSettings() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchClusterSettingsCreate(d *schema.ResourceData, meta interface{}) error {
-	err := resourceElasticsearchPutClusterSettings(d, meta)
+func resourceElasticsearchClusterSettingsCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	err := resourceElasticsearchPutClusterSettings(ctx, d, meta)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId("settings")
-	return resourceElasticsearchClusterSettingsRead(d, meta)
+	return resourceElasticsearchClusterSettingsRead(ctx, d, meta)
 }
 
-func resourceElasticsearchPutClusterSettings(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchPutClusterSettings(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	var err error
 
 	esClient, err := getClient(meta.(*ProviderConf))
@@ -307,7 +316,7 @@ func resourceElasticsearchPutClusterSettings(d *schema.ResourceData, meta interf
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		// elastic doesn't support PUTing settings: https://github.com/olivere/elastic/issues/1274
-		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "PUT",
 			Path:   "/_cluster/settings",
 			Body:   string(body),
@@ -316,7 +325,7 @@ func resourceElasticsearchPutClusterSettings(d *schema.ResourceData, meta interf
 			return err
 		}
 	case *elastic6.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "PUT",
 			Path:   "/_cluster/settings",
 			Body:   string(body),
@@ -331,30 +340,30 @@ func resourceElasticsearchPutClusterSettings(d *schema.ResourceData, meta interf
 	return err
 }
 
-func resourceElasticsearchClusterSettingsRead(d *schema.ResourceData, meta interface{}) error {
-	settings, err := resourceElasticsearchClusterSettingsGet(meta)
+func resourceElasticsearchClusterSettingsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	settings, err := resourceElasticsearchClusterSettingsGet(ctx, meta)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return clusterResourceDataFromSettings(settings["persistent"].(map[string]interface{}), d)
+	return diag.FromErr(clusterResourceDataFromSettings(settings["persistent"].(map[string]interface{}), d))
 }
 
-func resourceElasticsearchClusterSettingsUpdate(d *schema.ResourceData, meta interface{}) error {
-	return resourceElasticsearchPutClusterSettings(d, meta)
+func resourceElasticsearchClusterSettingsUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return diag.FromErr(resourceElasticsearchPutClusterSettings(ctx, d, meta))
 }
 
-func resourceElasticsearchClusterSettingsDelete(d *schema.ResourceData, meta interface{}) error {
-	err := clearAllSettings(meta)
+func resourceElasticsearchClusterSettingsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	err := clearAllSettings(ctx, meta)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId("")
-	return err
+	return diag.FromErr(err)
 }
 
-func resourceElasticsearchClusterSettingsGet(meta interface{}) (map[string]interface{}, error) {
+func resourceElasticsearchClusterSettingsGet(ctx context.Context, meta interface{}) (map[string]interface{}, error) {
 	var err error
 	var settings map[string]interface{}
 	var response *json.RawMessage
@@ -368,7 +377,7 @@ func resourceElasticsearchClusterSettingsGet(meta interface{}) (map[string]inter
 	case *elastic7.Client:
 		var res *elastic7.Response
 
-		res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err := client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "GET",
 			Path:   "/_cluster/settings?flat_settings=true",
 		})
@@ -379,7 +388,7 @@ func resourceElasticsearchClusterSettingsGet(meta interface{}) (map[string]inter
 	case *elastic6.Client:
 		var res *elastic6.Response
 
-		res, err := client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		res, err := client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "GET",
 			Path:   "/_cluster/settings?flat_settings=true",
 		})
@@ -399,7 +408,7 @@ func resourceElasticsearchClusterSettingsGet(meta interface{}) (map[string]inter
 	return settings, err
 }
 
-func clearAllSettings(meta interface{}) error {
+func clearAllSettings(ctx context.Context, meta interface{}) error {
 	var err error
 
 	esClient, err := getClient(meta.(*ProviderConf))
@@ -419,7 +428,7 @@ func clearAllSettings(meta interface{}) error {
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "PUT",
 			Path:   "/_cluster/settings",
 			Body:   body,
@@ -428,7 +437,7 @@ func clearAllSettings(meta interface{}) error {
 			return err
 		}
 	case *elastic6.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "PUT",
 			Path:   "/_cluster/settings",
 			Body:   body,
@@ -1,6 +1,7 @@
 package es
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"testing"
@@ -43,7 +44,7 @@ func testCheckElasticsearchClusterSettingInState(name string) resource.TestCheck
 func testCheckElasticsearchClusterSettingExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		meta := testAccProvider.Meta()
-		settings, err := resourceElasticsearchClusterSettingsGet(meta)
+		settings, err := resourceElasticsearchClusterSettingsGet(context.TODO(), meta)
 		if err != nil {
 			return err
 		}
@@ -65,7 +66,7 @@ func checkElasticsearchClusterSettingsDestroy(s *terraform.State) error {
 		}
 
 		meta := testAccProvider.Meta()
-		settings, err := resourceElasticsearchClusterSettingsGet(meta)
+		settings, err := resourceElasticsearchClusterSettingsGet(context.TODO(), meta)
 		if err != nil {
 			return err
 		}
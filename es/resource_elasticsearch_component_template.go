@@ -3,23 +3,23 @@ package es
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"log"
+	"time"
 
-	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	elastic7 "github.com/olivere/elastic/v7"
 )
 
-var esComponentTemplateMinimalVersion, _ = version.NewVersion("7.8.0")
-
 func resourceElasticsearchComponentTemplate() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchComponentTemplateCreate,
-		Read:   resourceElasticsearchComponentTemplateRead,
-		Update: resourceElasticsearchComponentTemplateUpdate,
-		Delete: resourceElasticsearchComponentTemplateDelete,
+		CreateContext: resourceElasticsearchComponentTemplateCreate,
+		ReadContext:   resourceElasticsearchComponentTemplateRead,
+		UpdateContext: resourceElasticsearchComponentTemplateUpdate,
+		DeleteContext: resourceElasticsearchComponentTemplateDelete,
+		CustomizeDiff: capabilityCustomizeDiff("elasticsearch_component_template"),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -39,42 +39,42 @@ func resourceElasticsearchComponentTemplate() *schema.Resource {
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 		Description: "Component templates are building blocks for constructing index templates that specify index mappings, settings, and aliases. You cannot directly apply a component template to a data stream or index. To be applied, a component template must be included in an index template’s `composed_of` list.",
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchComponentTemplateCreate(d *schema.ResourceData, meta interface{}) error {
-	err := resourceElasticsearchPutComponentTemplate(d, meta, true)
+func resourceElasticsearchComponentTemplateCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	err := resourceElasticsearchPutComponentTemplate(ctx, d, meta, true)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId(d.Get("name").(string))
 	return nil
 }
 
-func resourceElasticsearchComponentTemplateRead(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchComponentTemplateRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
 	var result string
-	var elasticVersion *version.Version
+	if err := checkCapability(meta, "elasticsearch_component_template"); err != nil {
+		return diag.FromErr(err)
+	}
 
-	providerConf := meta.(*ProviderConf)
-	esClient, err := getClient(providerConf)
+	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		elasticVersion, err = version.NewVersion(providerConf.esVersion)
-		if err == nil {
-			if resourceElasticsearchComponentTemplateAvailable(elasticVersion, providerConf) {
-				result, err = elastic7GetComponentTemplate(client, id)
-			} else {
-				err = fmt.Errorf("component_template endpoint only available from ElasticSearch >= 7.8, got version %s", elasticVersion.String())
-			}
-		}
+		result, err = elastic7GetComponentTemplate(ctx, client, id)
 	default:
-		err = fmt.Errorf("component_template endpoint only available from ElasticSearch >= 7.8, got version < 7.0.0")
+		err = errors.New("Elasticsearch version not supported")
 	}
 	if err != nil {
 		if elastic7.IsNotFound(err) {
@@ -83,17 +83,17 @@ func resourceElasticsearchComponentTemplateRead(d *schema.ResourceData, meta int
 			return nil
 		}
 
-		return err
+		return diag.FromErr(err)
 	}
 
 	ds := &resourceDataSetter{d: d}
 	ds.set("name", d.Id())
 	ds.set("body", result)
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func elastic7GetComponentTemplate(client *elastic7.Client, id string) (string, error) {
-	res, err := client.IndexGetComponentTemplate(id).Do(context.TODO())
+func elastic7GetComponentTemplate(ctx context.Context, client *elastic7.Client, id string) (string, error) {
+	res, err := client.IndexGetComponentTemplate(id).Do(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -108,81 +108,65 @@ func elastic7GetComponentTemplate(client *elastic7.Client, id string) (string, e
 	return string(tj), nil
 }
 
-func resourceElasticsearchComponentTemplateUpdate(d *schema.ResourceData, meta interface{}) error {
-	return resourceElasticsearchPutComponentTemplate(d, meta, false)
+func resourceElasticsearchComponentTemplateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return diag.FromErr(resourceElasticsearchPutComponentTemplate(ctx, d, meta, false))
 }
 
-func resourceElasticsearchComponentTemplateDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchComponentTemplateDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
-	var elasticVersion *version.Version
+	if err := checkCapability(meta, "elasticsearch_component_template"); err != nil {
+		return diag.FromErr(err)
+	}
 
-	providerConf := meta.(*ProviderConf)
-	esClient, err := getClient(providerConf)
+	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		elasticVersion, err = version.NewVersion(providerConf.esVersion)
-		if err == nil {
-			if resourceElasticsearchComponentTemplateAvailable(elasticVersion, providerConf) {
-				err = elastic7DeleteComponentTemplate(client, id)
-			} else {
-				err = fmt.Errorf("component_template endpoint only available from ElasticSearch >= 7.8, got version %s", elasticVersion.String())
-			}
-		}
+		err = elastic7DeleteComponentTemplate(ctx, client, id)
 	default:
-		err = fmt.Errorf("component_template endpoint only available from ElasticSearch >= 7.8, got version < 7.0.0")
+		err = errors.New("Elasticsearch version not supported")
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId("")
 	return nil
 }
 
-func resourceElasticsearchComponentTemplateAvailable(v *version.Version, c *ProviderConf) bool {
-	return v.GreaterThanOrEqual(esComponentTemplateMinimalVersion) || c.flavor == Unknown
-}
-
-func elastic7DeleteComponentTemplate(client *elastic7.Client, id string) error {
-	_, err := client.IndexDeleteComponentTemplate(id).Do(context.TODO())
+func elastic7DeleteComponentTemplate(ctx context.Context, client *elastic7.Client, id string) error {
+	_, err := client.IndexDeleteComponentTemplate(id).Do(ctx)
 	return err
 }
 
-func resourceElasticsearchPutComponentTemplate(d *schema.ResourceData, meta interface{}, create bool) error {
+func resourceElasticsearchPutComponentTemplate(ctx context.Context, d *schema.ResourceData, meta interface{}, create bool) error {
 	name := d.Get("name").(string)
 	body := d.Get("body").(string)
 
-	var elasticVersion *version.Version
+	if err := checkCapability(meta, "elasticsearch_component_template"); err != nil {
+		return err
+	}
 
-	providerConf := meta.(*ProviderConf)
-	esClient, err := getClient(providerConf)
+	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
 		return err
 	}
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		elasticVersion, err = version.NewVersion(providerConf.esVersion)
-		if err == nil {
-			if resourceElasticsearchComponentTemplateAvailable(elasticVersion, providerConf) {
-				err = elastic7PutComponentTemplate(client, name, body, create)
-			} else {
-				err = fmt.Errorf("component_template endpoint only available from ElasticSearch >= 7.8, got version %s", elasticVersion.String())
-			}
-		}
+		err = elastic7PutComponentTemplate(ctx, client, name, body, create)
 	default:
-		err = fmt.Errorf("component_template endpoint only available from ElasticSearch >= 7.8, got version < 7.0.0")
+		err = errors.New("Elasticsearch version not supported")
 	}
 
 	return err
 }
 
-func elastic7PutComponentTemplate(client *elastic7.Client, name string, body string, create bool) error {
-	_, err := client.IndexPutComponentTemplate(name).BodyString(body).Create(create).Do(context.TODO())
+func elastic7PutComponentTemplate(ctx context.Context, client *elastic7.Client, name string, body string, create bool) error {
+	_, err := client.IndexPutComponentTemplate(name).BodyString(body).Create(create).Do(ctx)
 	return err
 }
@@ -3,23 +3,23 @@ package es
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"log"
+	"time"
 
-	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	elastic7 "github.com/olivere/elastic/v7"
 )
 
-var minimalESComposableTemplateVersion, _ = version.NewVersion("7.8.0")
-
 func resourceElasticsearchComposableIndexTemplate() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchComposableIndexTemplateCreate,
-		Read:   resourceElasticsearchComposableIndexTemplateRead,
-		Update: resourceElasticsearchComposableIndexTemplateUpdate,
-		Delete: resourceElasticsearchComposableIndexTemplateDelete,
+		CreateContext: resourceElasticsearchComposableIndexTemplateCreate,
+		ReadContext:   resourceElasticsearchComposableIndexTemplateRead,
+		UpdateContext: resourceElasticsearchComposableIndexTemplateUpdate,
+		DeleteContext: resourceElasticsearchComposableIndexTemplateDelete,
+		CustomizeDiff: capabilityCustomizeDiff("elasticsearch_composable_index_template"),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -36,46 +36,42 @@ func resourceElasticsearchComposableIndexTemplate() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchComposableIndexTemplateCreate(d *schema.ResourceData, meta interface{}) error {
-	err := resourceElasticsearchPutComposableIndexTemplate(d, meta, true)
+func resourceElasticsearchComposableIndexTemplateCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	err := resourceElasticsearchPutComposableIndexTemplate(ctx, d, meta, true)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId(d.Get("name").(string))
 	return nil
 }
 
-func resourceElasticsearchComposableIndexTemplateAvailable(v *version.Version, c *ProviderConf) bool {
-	return v.GreaterThanOrEqual(minimalESComposableTemplateVersion) || c.flavor == Unknown
-}
-
-func resourceElasticsearchComposableIndexTemplateRead(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchComposableIndexTemplateRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
 	var result string
-	var elasticVersion *version.Version
+	if err := checkCapability(meta, "elasticsearch_composable_index_template"); err != nil {
+		return diag.FromErr(err)
+	}
 
-	providerConf := meta.(*ProviderConf)
-	esClient, err := getClient(providerConf)
+	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		elasticVersion, err = version.NewVersion(providerConf.esVersion)
-		if err == nil {
-			if resourceElasticsearchComposableIndexTemplateAvailable(elasticVersion, providerConf) {
-				result, err = elastic7GetIndexTemplate(client, id)
-			} else {
-				err = fmt.Errorf("index_template endpoint only available from ElasticSearch >= 7.8, got version %s", elasticVersion.String())
-			}
-		}
+		result, err = elastic7GetIndexTemplate(ctx, client, id)
 	default:
-		err = fmt.Errorf("index_template endpoint only available from ElasticSearch >= 7.8, got version < 7.0.0")
+		err = errors.New("Elasticsearch version not supported")
 	}
 	if err != nil {
 		if elastic7.IsNotFound(err) {
@@ -84,17 +80,17 @@ func resourceElasticsearchComposableIndexTemplateRead(d *schema.ResourceData, me
 			return nil
 		}
 
-		return err
+		return diag.FromErr(err)
 	}
 
 	ds := &resourceDataSetter{d: d}
 	ds.set("name", d.Id())
 	ds.set("body", result)
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func elastic7GetIndexTemplate(client *elastic7.Client, id string) (string, error) {
-	res, err := client.IndexGetIndexTemplate(id).Do(context.TODO())
+func elastic7GetIndexTemplate(ctx context.Context, client *elastic7.Client, id string) (string, error) {
+	res, err := client.IndexGetIndexTemplate(id).Do(ctx)
 	log.Printf("[INFO] Index template %+v %+v", res, err)
 	if err != nil {
 		return "", err
@@ -110,77 +106,65 @@ func elastic7GetIndexTemplate(client *elastic7.Client, id string) (string, error
 	return string(tj), nil
 }
 
-func resourceElasticsearchComposableIndexTemplateUpdate(d *schema.ResourceData, meta interface{}) error {
-	return resourceElasticsearchPutComposableIndexTemplate(d, meta, false)
+func resourceElasticsearchComposableIndexTemplateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return diag.FromErr(resourceElasticsearchPutComposableIndexTemplate(ctx, d, meta, false))
 }
 
-func resourceElasticsearchComposableIndexTemplateDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchComposableIndexTemplateDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
-	var elasticVersion *version.Version
+	if err := checkCapability(meta, "elasticsearch_composable_index_template"); err != nil {
+		return diag.FromErr(err)
+	}
 
-	providerConf := meta.(*ProviderConf)
-	esClient, err := getClient(providerConf)
+	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		elasticVersion, err = version.NewVersion(providerConf.esVersion)
-		if err == nil {
-			if resourceElasticsearchComposableIndexTemplateAvailable(elasticVersion, providerConf) {
-				err = elastic7DeleteIndexTemplate(client, id)
-			} else {
-				err = fmt.Errorf("index_template endpoint only available from ElasticSearch >= 7.8, got version %s", elasticVersion.String())
-			}
-		}
+		err = elastic7DeleteIndexTemplate(ctx, client, id)
 	default:
-		err = fmt.Errorf("index_template endpoint only available from ElasticSearch >= 7.8, got version < 7.0.0")
+		err = errors.New("Elasticsearch version not supported")
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId("")
 	return nil
 }
 
-func elastic7DeleteIndexTemplate(client *elastic7.Client, id string) error {
-	_, err := client.IndexDeleteIndexTemplate(id).Do(context.TODO())
+func elastic7DeleteIndexTemplate(ctx context.Context, client *elastic7.Client, id string) error {
+	_, err := client.IndexDeleteIndexTemplate(id).Do(ctx)
 	return err
 }
 
-func resourceElasticsearchPutComposableIndexTemplate(d *schema.ResourceData, meta interface{}, create bool) error {
+func resourceElasticsearchPutComposableIndexTemplate(ctx context.Context, d *schema.ResourceData, meta interface{}, create bool) error {
 	name := d.Get("name").(string)
 	body := d.Get("body").(string)
 
-	var elasticVersion *version.Version
+	if err := checkCapability(meta, "elasticsearch_composable_index_template"); err != nil {
+		return err
+	}
 
-	providerConf := meta.(*ProviderConf)
-	esClient, err := getClient(providerConf)
+	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
 		return err
 	}
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		elasticVersion, err = version.NewVersion(providerConf.esVersion)
-		if err == nil {
-			if resourceElasticsearchComposableIndexTemplateAvailable(elasticVersion, providerConf) {
-				err = elastic7PutIndexTemplate(client, name, body, create)
-			} else {
-				err = fmt.Errorf("index_template endpoint only available from ElasticSearch >= 7.8, got version %s", elasticVersion.String())
-			}
-		}
+		err = elastic7PutIndexTemplate(ctx, client, name, body, create)
 	default:
-		err = fmt.Errorf("index_template endpoint only available from ElasticSearch >= 7.8, got version < 7.0.0")
+		err = errors.New("Elasticsearch version not supported")
 	}
 
 	return err
 }
 
-func elastic7PutIndexTemplate(client *elastic7.Client, name string, body string, create bool) error {
-	_, err := client.IndexPutIndexTemplate(name).BodyString(body).Create(create).Do(context.TODO())
+func elastic7PutIndexTemplate(ctx context.Context, client *elastic7.Client, name string, body string, create bool) error {
+	_, err := client.IndexPutIndexTemplate(name).BodyString(body).Create(create).Do(ctx)
 	return err
 }
@@ -2,25 +2,25 @@ package es
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
-	"github.com/hashicorp/go-version"
-
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 	"github.com/olivere/elastic/uritemplates"
 	elastic7 "github.com/olivere/elastic/v7"
 )
 
-var minimalESDataStreamVersion, _ = version.NewVersion("7.9.0")
-
 func resourceElasticsearchDataStream() *schema.Resource {
 	return &schema.Resource{
-		Description: "A data stream lets you store append-only time series data across multiple (hidden, auto-generated) indices while giving you a single named resource for requests. See the [guide](https://www.elastic.co/guide/en/elasticsearch/reference/7.17/data-streams.html) and [API docs](https://www.elastic.co/guide/en/elasticsearch/reference/7.17/data-stream-apis.html).",
-		Create:      resourceElasticsearchDataStreamCreate,
-		Read:        resourceElasticsearchDataStreamRead,
-		Delete:      resourceElasticsearchDataStreamDelete,
+		Description:   "A data stream lets you store append-only time series data across multiple (hidden, auto-generated) indices while giving you a single named resource for requests. See the [guide](https://www.elastic.co/guide/en/elasticsearch/reference/7.17/data-streams.html) and [API docs](https://www.elastic.co/guide/en/elasticsearch/reference/7.17/data-stream-apis.html).",
+		CreateContext: resourceElasticsearchDataStreamCreate,
+		ReadContext:   resourceElasticsearchDataStreamRead,
+		DeleteContext: resourceElasticsearchDataStreamDelete,
+		CustomizeDiff: capabilityCustomizeDiff("elasticsearch_data_stream"),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -32,45 +32,40 @@ func resourceElasticsearchDataStream() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchDataStreamCreate(d *schema.ResourceData, meta interface{}) error {
-	err := resourceElasticsearchPutDataStream(d, meta)
+func resourceElasticsearchDataStreamCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	err := resourceElasticsearchPutDataStream(ctx, d, meta)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId(d.Get("name").(string))
-	return resourceElasticsearchDataStreamRead(d, meta)
+	return resourceElasticsearchDataStreamRead(ctx, d, meta)
 }
 
-func resourceElasticsearchDataStreamAvailable(v *version.Version, c *ProviderConf) bool {
-	return v.GreaterThanOrEqual(minimalESDataStreamVersion) || c.flavor == Unknown
-}
-
-func resourceElasticsearchDataStreamRead(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchDataStreamRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
-	var elasticVersion *version.Version
+	if err := checkCapability(meta, "elasticsearch_data_stream"); err != nil {
+		return diag.FromErr(err)
+	}
 
-	providerConf := meta.(*ProviderConf)
-	esClient, err := getClient(providerConf)
+	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		elasticVersion, err = version.NewVersion(providerConf.esVersion)
-		if err == nil {
-			if resourceElasticsearchDataStreamAvailable(elasticVersion, providerConf) {
-				err = elastic7GetDataStream(client, id)
-			} else {
-				err = fmt.Errorf("_data_stream endpoint only available from ElasticSearch >= 7.9, got version %s", elasticVersion.String())
-			}
-		}
+		err = elastic7GetDataStream(ctx, client, id)
 	default:
-		err = fmt.Errorf("_data_stream endpoint only available from ElasticSearch >= 7.9, got version < 7.0.0")
+		err = errors.New("Elasticsearch version not supported")
 	}
 	if err != nil {
 		if elastic7.IsNotFound(err) {
@@ -79,75 +74,63 @@ func resourceElasticsearchDataStreamRead(d *schema.ResourceData, meta interface{
 			return nil
 		}
 
-		return err
+		return diag.FromErr(err)
 	}
 
 	ds := &resourceDataSetter{d: d}
 	ds.set("name", d.Id())
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func resourceElasticsearchDataStreamDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchDataStreamDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
-	var elasticVersion *version.Version
+	if err := checkCapability(meta, "elasticsearch_data_stream"); err != nil {
+		return diag.FromErr(err)
+	}
 
-	providerConf := meta.(*ProviderConf)
-	esClient, err := getClient(providerConf)
+	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		elasticVersion, err = version.NewVersion(providerConf.esVersion)
-		if err == nil {
-			if resourceElasticsearchDataStreamAvailable(elasticVersion, providerConf) {
-				err = elastic7DeleteDataStream(client, id)
-			} else {
-				err = fmt.Errorf("_data_stream endpoint only available from ElasticSearch >= 7.9, got version %s", elasticVersion.String())
-			}
-		}
+		err = elastic7DeleteDataStream(ctx, client, id)
 	default:
-		err = fmt.Errorf("_data_stream endpoint only available from ElasticSearch >= 7.9, got version < 7.0.0")
+		err = errors.New("Elasticsearch version not supported")
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId("")
 	return nil
 }
 
-func resourceElasticsearchPutDataStream(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchPutDataStream(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	name := d.Get("name").(string)
 
-	var elasticVersion *version.Version
+	if err := checkCapability(meta, "elasticsearch_data_stream"); err != nil {
+		return err
+	}
 
-	providerConf := meta.(*ProviderConf)
-	esClient, err := getClient(providerConf)
+	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
 		return err
 	}
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		elasticVersion, err = version.NewVersion(providerConf.esVersion)
-		if err == nil {
-			if resourceElasticsearchDataStreamAvailable(elasticVersion, providerConf) {
-				err = elastic7PutDataStream(client, name)
-			} else {
-				err = fmt.Errorf("_data_stream endpoint only available from ElasticSearch >= 7.9, got version %s", elasticVersion.String())
-			}
-		}
+		err = elastic7PutDataStream(ctx, client, name)
 	default:
-		err = fmt.Errorf("_data_stream endpoint only available from ElasticSearch >= 7.9, got version < 7.0.0")
+		err = errors.New("Elasticsearch version not supported")
 	}
 
 	return err
 }
 
-func elastic7GetDataStream(client *elastic7.Client, id string) error {
+func elastic7GetDataStream(ctx context.Context, client *elastic7.Client, id string) error {
 	path, err := uritemplates.Expand("/_data_stream/{id}", map[string]string{
 		"id": id,
 	})
@@ -155,14 +138,14 @@ func elastic7GetDataStream(client *elastic7.Client, id string) error {
 		return fmt.Errorf("error building URL path for data stream: %+v", err)
 	}
 
-	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+	_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 		Method: "GET",
 		Path:   path,
 	})
 	return err
 }
 
-func elastic7DeleteDataStream(client *elastic7.Client, id string) error {
+func elastic7DeleteDataStream(ctx context.Context, client *elastic7.Client, id string) error {
 	path, err := uritemplates.Expand("/_data_stream/{id}", map[string]string{
 		"id": id,
 	})
@@ -170,14 +153,14 @@ func elastic7DeleteDataStream(client *elastic7.Client, id string) error {
 		return fmt.Errorf("error building URL path for data stream: %+v", err)
 	}
 
-	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+	_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 		Method: "DELETE",
 		Path:   path,
 	})
 	return err
 }
 
-func elastic7PutDataStream(client *elastic7.Client, id string) error {
+func elastic7PutDataStream(ctx context.Context, client *elastic7.Client, id string) error {
 	path, err := uritemplates.Expand("/_data_stream/{id}", map[string]string{
 		"id": id,
 	})
@@ -185,7 +168,7 @@ func elastic7PutDataStream(client *elastic7.Client, id string) error {
 		return fmt.Errorf("error building URL path for data stream: %+v", err)
 	}
 
-	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+	_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 		Method: "PUT",
 		Path:   path,
 	})
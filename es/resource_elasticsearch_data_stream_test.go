@@ -73,7 +73,7 @@ func testCheckElasticsearchDataStreamExists(name string) resource.TestCheckFunc
 		}
 		switch client := esClient.(type) {
 		case *elastic7.Client:
-			err = elastic7GetDataStream(client, rs.Primary.ID)
+			err = elastic7GetDataStream(context.TODO(), client, rs.Primary.ID)
 		default:
 			return errors.New("Elasticsearch version not supported")
 		}
@@ -101,7 +101,7 @@ func testCheckElasticsearchDataStreamDestroy(s *terraform.State) error {
 		}
 		switch client := esClient.(type) {
 		case *elastic7.Client:
-			err = elastic7GetDataStream(client, rs.Primary.ID)
+			err = elastic7GetDataStream(context.TODO(), client, rs.Primary.ID)
 		default:
 			return errors.New("Elasticsearch version not supported")
 		}
@@ -6,8 +6,15 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
@@ -70,6 +77,19 @@ var (
 		"indexing.slowlog.source",
 	}
 	settingsKeys = append(staticSettingsKeys, dynamicsSettingsKeys...)
+	// closedIndexAttributes can only be updated on a closed index, they replace
+	// the index unless `allow_close_for_updates` is set
+	closedIndexAttributes = []string{
+		"codec",
+		"load_fixed_bitset_filters_eagerly",
+		"shard_check_on_startup",
+		"index_similarity_default",
+		"analysis_analyzer",
+		"analysis_tokenizer",
+		"analysis_filter",
+		"analysis_char_filter",
+		"analysis_normalizer",
+	}
 )
 
 var (
@@ -86,9 +106,15 @@ var (
 			Default:     false,
 			Optional:    true,
 		},
+		"allow_close_for_updates": {
+			Type:        schema.TypeBool,
+			Description: "A boolean that indicates that the index may be closed to update settings which can't be changed on an open index, such as analysis settings, and reopened once its shards are active. The index is unavailable while closed. If unset, changing these settings replaces the index.",
+			Default:     false,
+			Optional:    true,
+		},
 		"include_type_name": {
 			Type:        schema.TypeString,
-			Description: "A string that indicates if and what we should pass to include_type_name parameter. Set to `\"false\"` when trying to create an index on a v6 cluster without a doc type or set to `\"true\"` when trying to create an index on a v7 cluster with a doc type. Since mapping updates are not currently supported, this applies only on index create.",
+			Description: "A string that indicates if and what we should pass to include_type_name parameter. Set to `\"false\"` when trying to create an index on a v6 cluster without a doc type or set to `\"true\"` when trying to create an index on a v7 cluster with a doc type. This applies only on index create, mapping updates use the doc type of `mappings`, if any.",
 			Default:     "",
 			Optional:    true,
 		},
@@ -114,20 +140,17 @@ var (
 		},
 		"load_fixed_bitset_filters_eagerly": {
 			Type:        schema.TypeBool,
-			Description: "Indicates whether cached filters are pre-loaded for nested queries. This can be set only on creation.",
-			ForceNew:    true,
+			Description: "Indicates whether cached filters are pre-loaded for nested queries. Updates close the index if `allow_close_for_updates` is set, and replace it otherwise.",
 			Optional:    true,
 		},
 		"codec": {
 			Type:        schema.TypeString,
-			Description: "The `default` value compresses stored data with LZ4 compression, but this can be set to `best_compression` which uses DEFLATE for a higher compression ratio. This can be set only on creation.",
-			ForceNew:    true,
+			Description: "The `default` value compresses stored data with LZ4 compression, but this can be set to `best_compression` which uses DEFLATE for a higher compression ratio. Updates close the index if `allow_close_for_updates` is set, and replace it otherwise.",
 			Optional:    true,
 		},
 		"shard_check_on_startup": {
 			Type:        schema.TypeString,
-			Description: "Whether or not shards should be checked for corruption before opening. When corruption is detected, it will prevent the shard from being opened. Accepts `false`, `true`, `checksum`.",
-			ForceNew:    true,
+			Description: "Whether or not shards should be checked for corruption before opening. When corruption is detected, it will prevent the shard from being opened. Accepts `false`, `true`, `checksum`. Updates close the index if `allow_close_for_updates` is set, and replace it otherwise.",
 			Optional:    true,
 		},
 		"sort_field": {
@@ -143,11 +166,11 @@ var (
 			Optional:    true,
 		},
 		"index_similarity_default": {
-			Type:         schema.TypeString,
-			Description:  "A JSON string describing the default index similarity config.",
-			Optional:     true,
-			ForceNew:     true, // To update index similarity config, the index must be closed, updated, and then reopened; we can't handle that here.
-			ValidateFunc: validation.StringIsJSON,
+			Type:             schema.TypeString,
+			Description:      "A JSON string describing the default index similarity config. Updates close the index if `allow_close_for_updates` is set, and replace it otherwise.",
+			Optional:         true,
+			DiffSuppressFunc: diffSuppressIndexSettingsJSON,
+			ValidateFunc:     validation.StringIsJSON,
 		},
 		// Dynamic settings that can be changed at runtime
 		"number_of_replicas": {
@@ -352,55 +375,60 @@ var (
 		},
 		// Other attributes
 		"mappings": {
-			Type:         schema.TypeString,
-			Description:  "A JSON string defining how documents in the index, and the fields they contain, are stored and indexed. To avoid the complexities of field mapping updates, updates of this field are not allowed via this provider. See the upstream [Elasticsearch docs](https://www.elastic.co/guide/en/elasticsearch/reference/6.8/indices-put-mapping.html#updating-field-mappings) for more details.",
-			Optional:     true,
-			ForceNew:     true,
-			ValidateFunc: validation.StringIsJSON,
+			Type:             schema.TypeString,
+			Description:      "A JSON string defining how documents in the index, and the fields they contain, are stored and indexed. Additive changes, such as new fields, new multi-fields, raising `ignore_above` or adding runtime fields, are applied in place with the put mapping API. Other changes can't be applied to an existing index, they replace the index if `force_destroy` is set and fail the plan otherwise. Fields missing from this attribute are ignored where dynamic mapping is enabled, as indexing documents may have added them. See the upstream [Elasticsearch docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-put-mapping.html#updating-field-mappings) for more details.",
+			Optional:         true,
+			DiffSuppressFunc: diffSuppressIndexMappings,
+			ValidateFunc:     validation.StringIsJSON,
 		},
 		"aliases": {
-			Type:        schema.TypeString,
-			Description: "A JSON string describing a set of aliases. The index aliases API allows aliasing an index with a name, with all APIs automatically converting the alias name to the actual index name. An alias can also be mapped to more than one index, and when specifying it, the alias will automatically expand to the aliased indices.",
-			Optional:    true,
-			// In order to not handle the separate endpoint of alias updates, updates
-			// are not allowed via this provider currently.
-			ForceNew:     true,
-			ValidateFunc: validation.StringIsJSON,
+			Type:             schema.TypeString,
+			Description:      "A JSON string describing a set of aliases. The index aliases API allows aliasing an index with a name, with all APIs automatically converting the alias name to the actual index name. An alias can also be mapped to more than one index, and when specifying it, the alias will automatically expand to the aliased indices. Changes are applied atomically with a single request to the aliases API.",
+			Optional:         true,
+			DiffSuppressFunc: diffSuppressIndexAliases,
+			ValidateFunc:     validation.StringIsJSON,
 		},
 		"analysis_analyzer": {
-			Type:         schema.TypeString,
-			Description:  "A JSON string describing the analyzers applied to the index.",
-			Optional:     true,
-			ForceNew:     true, // To add an analyzer, the index must be closed, updated, and then reopened; we can't handle that here.
-			ValidateFunc: validation.StringIsJSON,
+			Type:             schema.TypeString,
+			Description:      "A JSON string describing the analyzers applied to the index. Updates close the index if `allow_close_for_updates` is set, and replace it otherwise.",
+			Optional:         true,
+			DiffSuppressFunc: diffSuppressIndexSettingsJSON,
+			ValidateFunc:     validation.StringIsJSON,
 		},
 		"analysis_tokenizer": {
-			Type:         schema.TypeString,
-			Description:  "A JSON string describing the tokenizers applied to the index.",
-			Optional:     true,
-			ForceNew:     true, // To add a tokenizer, the index must be closed, updated, and then reopened; we can't handle that here.
-			ValidateFunc: validation.StringIsJSON,
+			Type:             schema.TypeString,
+			Description:      "A JSON string describing the tokenizers applied to the index. Updates close the index if `allow_close_for_updates` is set, and replace it otherwise.",
+			Optional:         true,
+			DiffSuppressFunc: diffSuppressIndexSettingsJSON,
+			ValidateFunc:     validation.StringIsJSON,
 		},
 		"analysis_filter": {
-			Type:         schema.TypeString,
-			Description:  "A JSON string describing the filters applied to the index.",
-			Optional:     true,
-			ForceNew:     true, // To add a filter, the index must be closed, updated, and then reopened; we can't handle that here.
-			ValidateFunc: validation.StringIsJSON,
+			Type:             schema.TypeString,
+			Description:      "A JSON string describing the filters applied to the index. Updates close the index if `allow_close_for_updates` is set, and replace it otherwise.",
+			Optional:         true,
+			DiffSuppressFunc: diffSuppressIndexSettingsJSON,
+			ValidateFunc:     validation.StringIsJSON,
 		},
 		"analysis_char_filter": {
-			Type:         schema.TypeString,
-			Description:  "A JSON string describing the char_filters applied to the index.",
-			Optional:     true,
-			ForceNew:     true, // To add a char_filters, the index must be closed, updated, and then reopened; we can't handle that here.
-			ValidateFunc: validation.StringIsJSON,
+			Type:             schema.TypeString,
+			Description:      "A JSON string describing the char_filters applied to the index. Updates close the index if `allow_close_for_updates` is set, and replace it otherwise.",
+			Optional:         true,
+			DiffSuppressFunc: diffSuppressIndexSettingsJSON,
+			ValidateFunc:     validation.StringIsJSON,
 		},
 		"analysis_normalizer": {
-			Type:         schema.TypeString,
-			Description:  "A JSON string describing the normalizers applied to the index.",
-			Optional:     true,
-			ForceNew:     true, // To add a normalizer, the index must be closed, updated, and then reopened; we can't handle that here.
-			ValidateFunc: validation.StringIsJSON,
+			Type:             schema.TypeString,
+			Description:      "A JSON string describing the normalizers applied to the index. Updates close the index if `allow_close_for_updates` is set, and replace it otherwise.",
+			Optional:         true,
+			DiffSuppressFunc: diffSuppressIndexSettingsJSON,
+			ValidateFunc:     validation.StringIsJSON,
+		},
+		"settings_raw": {
+			Type:             schema.TypeString,
+			Description:      "A JSON string of index settings which don't have a dedicated attribute, e.g. `index.lifecycle.name`, `index.routing.allocation.require.*`, `index.mapping.total_fields.limit` or plugin settings. Only the settings declared here are read back. Changing a static setting replaces the index, or closes it if `allow_close_for_updates` is set and the setting isn't fixed at creation, such as `index.sort.*`.",
+			Optional:         true,
+			DiffSuppressFunc: diffSuppressIndexSettingsRaw,
+			ValidateFunc:     validateIndexSettingsRaw,
 		},
 		// Computed attributes
 		"rollover_alias": {
@@ -413,26 +441,34 @@ var (
 
 func resourceElasticsearchIndex() *schema.Resource {
 	return &schema.Resource{
-		Description: "Provides an Elasticsearch index resource.",
-		Create:      resourceElasticsearchIndexCreate,
-		Read:        resourceElasticsearchIndexRead,
-		Update:      resourceElasticsearchIndexUpdate,
-		Delete:      resourceElasticsearchIndexDelete,
-		Schema:      configSchema,
+		Description:   "Provides an Elasticsearch index resource.",
+		CreateContext: resourceElasticsearchIndexCreate,
+		ReadContext:   resourceElasticsearchIndexRead,
+		UpdateContext: resourceElasticsearchIndexUpdate,
+		DeleteContext: resourceElasticsearchIndexDelete,
+		CustomizeDiff: resourceElasticsearchIndexCustomizeDiff,
+		Schema:        configSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchIndexCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchIndexCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	var (
-		name     = d.Get("name").(string)
-		settings = settingsFromIndexResourceData(d)
-		body     = make(map[string]interface{})
-		ctx      = context.Background()
-		err      error
+		name = d.Get("name").(string)
+		body = make(map[string]interface{})
 	)
+	settings, err := settingsFromIndexResourceData(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	if len(settings) > 0 {
 		body["settings"] = settings
 	}
@@ -442,7 +478,7 @@ func resourceElasticsearchIndexCreate(d *schema.ResourceData, meta interface{})
 		bytes := []byte(aliasJSON.(string))
 		err = json.Unmarshal(bytes, &aliases)
 		if err != nil {
-			return fmt.Errorf("fail to unmarshal: %v", err)
+			return diag.Errorf("fail to unmarshal: %v", err)
 		}
 		body["aliases"] = aliases
 	}
@@ -455,7 +491,7 @@ func resourceElasticsearchIndexCreate(d *schema.ResourceData, meta interface{})
 		bytes := []byte(analyzerJSON.(string))
 		err = json.Unmarshal(bytes, &analyzer)
 		if err != nil {
-			return fmt.Errorf("fail to unmarshal: %v", err)
+			return diag.Errorf("fail to unmarshal: %v", err)
 		}
 		analysis["analyzer"] = analyzer
 	}
@@ -464,7 +500,7 @@ func resourceElasticsearchIndexCreate(d *schema.ResourceData, meta interface{})
 		bytes := []byte(tokenizerJSON.(string))
 		err = json.Unmarshal(bytes, &tokenizer)
 		if err != nil {
-			return fmt.Errorf("fail to unmarshal: %v", err)
+			return diag.Errorf("fail to unmarshal: %v", err)
 		}
 		analysis["tokenizer"] = tokenizer
 	}
@@ -473,7 +509,7 @@ func resourceElasticsearchIndexCreate(d *schema.ResourceData, meta interface{})
 		bytes := []byte(filterJSON.(string))
 		err = json.Unmarshal(bytes, &filter)
 		if err != nil {
-			return fmt.Errorf("fail to unmarshal: %v", err)
+			return diag.Errorf("fail to unmarshal: %v", err)
 		}
 		analysis["filter"] = filter
 	}
@@ -482,7 +518,7 @@ func resourceElasticsearchIndexCreate(d *schema.ResourceData, meta interface{})
 		bytes := []byte(filterJSON.(string))
 		err = json.Unmarshal(bytes, &filter)
 		if err != nil {
-			return fmt.Errorf("fail to unmarshal: %v", err)
+			return diag.Errorf("fail to unmarshal: %v", err)
 		}
 		analysis["char_filter"] = filter
 	}
@@ -491,7 +527,7 @@ func resourceElasticsearchIndexCreate(d *schema.ResourceData, meta interface{})
 		bytes := []byte(normalizerJSON.(string))
 		err = json.Unmarshal(bytes, &normalizer)
 		if err != nil {
-			return fmt.Errorf("fail to unmarshal: %v", err)
+			return diag.Errorf("fail to unmarshal: %v", err)
 		}
 		analysis["normalizer"] = normalizer
 	}
@@ -501,7 +537,7 @@ func resourceElasticsearchIndexCreate(d *schema.ResourceData, meta interface{})
 		bytes := []byte(mappingsJSON.(string))
 		err = json.Unmarshal(bytes, &mappings)
 		if err != nil {
-			return fmt.Errorf("fail to unmarshal: %v", err)
+			return diag.Errorf("fail to unmarshal: %v", err)
 		}
 		body["mappings"] = mappings
 	}
@@ -512,7 +548,7 @@ func resourceElasticsearchIndexCreate(d *schema.ResourceData, meta interface{})
 		bytes := []byte(defaultIndexSimilarityJSON.(string))
 		err = json.Unmarshal(bytes, &defaultIndexSimilarity)
 		if err != nil {
-			return fmt.Errorf("fail to unmarshal: %v", err)
+			return diag.Errorf("fail to unmarshal: %v", err)
 		}
 		settings["index.similarity.default"] = defaultIndexSimilarity
 	}
@@ -525,7 +561,7 @@ func resourceElasticsearchIndexCreate(d *schema.ResourceData, meta interface{})
 	// non-URL friendly characters and functionality like date math
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
@@ -555,18 +591,18 @@ func resourceElasticsearchIndexCreate(d *schema.ResourceData, meta interface{})
 		}
 
 	default:
-		return errors.New("Elasticsearch version not supported")
+		return diag.FromErr(errors.New("Elasticsearch version not supported"))
 	}
 
 	if err == nil {
 		// Let terraform know the resource was created
 		d.SetId(resolvedName)
-		return resourceElasticsearchIndexRead(d, meta)
+		return resourceElasticsearchIndexRead(ctx, d, meta)
 	}
-	return err
+	return diag.FromErr(err)
 }
 
-func settingsFromIndexResourceData(d *schema.ResourceData) map[string]interface{} {
+func settingsFromIndexResourceData(d *schema.ResourceData) (map[string]interface{}, error) {
 	settings := make(map[string]interface{})
 	for _, key := range settingsKeys {
 		schemaName := strings.Replace(key, ".", "_", -1)
@@ -575,7 +611,15 @@ func settingsFromIndexResourceData(d *schema.ResourceData) map[string]interface{
 			settings[key] = raw
 		}
 	}
-	return settings
+
+	rawSettings, err := flatRawSettings(d.Get("settings_raw").(string))
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range rawSettings {
+		settings[key] = value
+	}
+	return settings, nil
 }
 
 func indexResourceDataFromSettings(settings map[string]interface{}, d *schema.ResourceData) {
@@ -600,26 +644,168 @@ func indexResourceDataFromSettings(settings map[string]interface{}, d *schema.Re
 	}
 }
 
-func resourceElasticsearchIndexDelete(d *schema.ResourceData, meta interface{}) error {
+// staticIndexSettings can only be changed on a closed index. Entries ending
+// with a dot match all the settings they prefix.
+var staticIndexSettings = []string{
+	"index.analysis.",
+	"index.codec",
+	"index.knn",
+	"index.load_fixed_bitset_filters_eagerly",
+	"index.queries.cache.enabled",
+	"index.shard.check_on_startup",
+	"index.similarity.",
+	"index.store.",
+}
+
+// finalIndexSettings can only be set when the index is created.
+var finalIndexSettings = []string{
+	"index.mode",
+	"index.number_of_routing_shards",
+	"index.number_of_shards",
+	"index.routing_partition_size",
+	"index.routing_path",
+	"index.soft_deletes.",
+	"index.sort.",
+}
+
+func matchesIndexSetting(key string, settings []string) bool {
+	for _, setting := range settings {
+		if key == setting || (strings.HasSuffix(setting, ".") && strings.HasPrefix(key, setting)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isStaticIndexSetting(key string) bool {
+	return matchesIndexSetting(key, staticIndexSettings) || isFinalIndexSetting(key)
+}
+
+func isFinalIndexSetting(key string) bool {
+	return matchesIndexSetting(key, finalIndexSettings)
+}
+
+// flatRawSettings returns the settings of `settings_raw` flattened, with
+// their keys prefixed with `index.`.
+func flatRawSettings(settingsJSON string) (map[string]interface{}, error) {
+	object, err := unmarshalJSONObject(settingsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]interface{})
+	for key, value := range flattenMap(object) {
+		settings[indexSettingKey(key)] = value
+	}
+	return settings, nil
+}
+
+// rawSettingsUpdate returns the settings of `settings_raw` which changed,
+// settings which were removed are set to null to reset them to their default.
+func rawSettingsUpdate(oldJSON, newJSON string) (map[string]interface{}, error) {
+	oldSettings, err := flatRawSettings(oldJSON)
+	if err != nil {
+		return nil, err
+	}
+	newSettings, err := flatRawSettings(newJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string]interface{})
+	for key := range oldSettings {
+		if _, ok := newSettings[key]; !ok {
+			changed[key] = nil
+		}
+	}
+	for key, value := range newSettings {
+		if fmt.Sprintf("%v", value) != fmt.Sprintf("%v", oldSettings[key]) {
+			changed[key] = value
+		}
+	}
+	return changed, nil
+}
+
+// indexSettingsRawFromSettings returns the flat settings of the index which
+// are declared in `settings_raw`, so other settings don't show as drift.
+func indexSettingsRawFromSettings(settings map[string]interface{}, settingsJSON string) (string, error) {
+	declared, err := flatRawSettings(settingsJSON)
+	if err != nil {
+		return "", err
+	}
+	if len(declared) == 0 {
+		return settingsJSON, nil
+	}
+
+	tracked := make(map[string]interface{})
+	for key := range declared {
+		if value, ok := settings[key]; ok {
+			tracked[key] = value
+		}
+	}
+	settingsRaw, err := json.Marshal(tracked)
+	if err != nil {
+		return "", err
+	}
+	return string(settingsRaw), nil
+}
+
+// validateIndexSettingsRaw checks `settings_raw` is a JSON object which
+// doesn't set settings managed by other attributes.
+func validateIndexSettingsRaw(i interface{}, k string) ([]string, []error) {
+	settings, err := flatRawSettings(i.(string))
+	if err != nil {
+		return nil, []error{fmt.Errorf("%q: %+v", k, err)}
+	}
+
+	managed := make(map[string]string)
+	for _, key := range settingsKeys {
+		managed[indexSettingKey(key)] = strings.Replace(key, ".", "_", -1)
+	}
+	for attr, key := range jsonSettingsAttributes {
+		managed[indexSettingKey(key)] = attr
+	}
+
+	var errs []error
+	for _, key := range unionKeys(settings, nil) {
+		for setting, attr := range managed {
+			if key == setting || strings.HasPrefix(key, setting+".") {
+				errs = append(errs, fmt.Errorf("%q: %s is managed by the `%s` attribute", k, key, attr))
+				break
+			}
+		}
+	}
+	return nil, errs
+}
+
+// indexSettingKey returns the key of an index setting prefixed with `index.`,
+// as returned by the get settings API.
+func indexSettingKey(key string) string {
+	if strings.HasPrefix(key, "index.") {
+		return key
+	}
+	return "index." + key
+}
+
+func resourceElasticsearchIndexDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	var (
 		name = d.Id()
-		ctx  = context.Background()
 		err  error
 	)
 
 	if alias, ok := d.GetOk("rollover_alias"); ok {
-		name = getWriteIndexByAlias(alias.(string), d, meta)
+		name = getWriteIndexByAlias(ctx, alias.(string), d, meta)
 	}
 
 	// check to see if there are documents in the index
-	allowed := allowIndexDestroy(name, d, meta)
+	allowed := allowIndexDestroy(ctx, name, d, meta)
 	if !allowed {
-		return fmt.Errorf("There are documents in the index (or the index could not be found), set force_destroy to true to allow destroying.")
+		return diag.Errorf("There are documents in the index (or the index could not be found), set force_destroy to true to allow destroying.")
 	}
 
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
@@ -632,14 +818,13 @@ func resourceElasticsearchIndexDelete(d *schema.ResourceData, meta interface{})
 		err = errors.New("Elasticsearch version not supported")
 	}
 
-	return err
+	return diag.FromErr(err)
 }
 
-func allowIndexDestroy(indexName string, d *schema.ResourceData, meta interface{}) bool {
+func allowIndexDestroy(ctx context.Context, indexName string, d *schema.ResourceData, meta interface{}) bool {
 	force := d.Get("force_destroy").(bool)
 
 	var (
-		ctx   = context.Background()
 		count int64
 		err   error
 	)
@@ -669,7 +854,7 @@ func allowIndexDestroy(indexName string, d *schema.ResourceData, meta interface{
 	return true
 }
 
-func resourceElasticsearchIndexUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchIndexUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	settings := make(map[string]interface{})
 	for _, key := range settingsKeys {
 		schemaName := strings.Replace(key, ".", "_", -1)
@@ -678,50 +863,732 @@ func resourceElasticsearchIndexUpdate(d *schema.ResourceData, meta interface{})
 		}
 	}
 
-	// if we're not changing any settings, no-op this function
-	if len(settings) == 0 {
-		return resourceElasticsearchIndexRead(d, meta)
+	// the JSON settings are merged by the put settings API, so they are sent
+	// flattened instead
+	delete(settings, "index.similarity.default")
+	for attr, key := range jsonSettingsAttributes {
+		if !d.HasChange(attr) {
+			continue
+		}
+		o, n := d.GetChange(attr)
+		changed, err := flatSettingsUpdate(key, o.(string), n.(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		for k, v := range changed {
+			settings[k] = v
+		}
+	}
+
+	closeIndex := false
+	for _, attr := range closedIndexAttributes {
+		if d.HasChange(attr) {
+			closeIndex = true
+		}
+	}
+
+	if d.HasChange("settings_raw") {
+		o, n := d.GetChange("settings_raw")
+		changed, err := rawSettingsUpdate(o.(string), n.(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		for key, value := range changed {
+			settings[key] = value
+			if isStaticIndexSetting(key) {
+				closeIndex = true
+			}
+		}
+	}
+
+	// if we're not changing any settings, mappings or aliases, no-op this function
+	if len(settings) == 0 && !d.HasChange("mappings") && !d.HasChange("aliases") {
+		return resourceElasticsearchIndexRead(ctx, d, meta)
+	}
+
+	name := d.Id()
+	if alias, ok := d.GetOk("rollover_alias"); ok {
+		name = getWriteIndexByAlias(ctx, alias.(string), d, meta)
+	}
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// settings are updated first, so new mapping fields can use new analyzers
+	if closeIndex {
+		err = putClosedIndexSettings(ctx, esClient, name, settings, d.Timeout(schema.TimeoutUpdate))
+	} else if len(settings) > 0 {
+		err = putIndexSettings(ctx, esClient, name, settings)
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.HasChange("mappings") {
+		o, n := d.GetChange("mappings")
+		if err := updateIndexMappings(ctx, esClient, name, o.(string), n.(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("aliases") {
+		o, n := d.GetChange("aliases")
+		if err := updateIndexAliases(ctx, esClient, name, o.(string), n.(string)); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 
+	return resourceElasticsearchIndexRead(ctx, d, meta.(*ProviderConf))
+}
+
+func putIndexSettings(ctx context.Context, esClient interface{}, name string, settings map[string]interface{}) error {
 	body := map[string]interface{}{
 		// Note you do not have to explicitly specify the `index` section inside
 		// the `settings` section
 		"settings": settings,
 	}
 
+	var err error
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.IndexPutSettings(name).BodyJson(body).Do(ctx)
+	case *elastic6.Client:
+		_, err = client.IndexPutSettings(name).BodyJson(body).Do(ctx)
+	default:
+		err = errors.New("Elasticsearch version not supported")
+	}
+	return err
+}
+
+// putClosedIndexSettings closes the index, puts settings which can't be
+// changed on an open index and reopens it, waiting for its primary shards to
+// be active. The index is reopened even if the settings couldn't be put.
+func putClosedIndexSettings(ctx context.Context, esClient interface{}, name string, settings map[string]interface{}, timeout time.Duration) (err error) {
+	log.Printf("[INFO] Closing index %s to update its settings", name)
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.CloseIndex(name).Do(ctx)
+	case *elastic6.Client:
+		_, err = client.CloseIndex(name).Do(ctx)
+	default:
+		return errors.New("Elasticsearch version not supported")
+	}
+
+	defer func() {
+		// reopen with a new context, so the index is reopened even if the
+		// update timed out or was interrupted
+		openCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if openErr := openIndex(openCtx, esClient, name, timeout); openErr != nil {
+			if err != nil {
+				err = fmt.Errorf("%+v, and error reopening index %s, it is closed: %+v", err, name, openErr)
+			} else {
+				err = fmt.Errorf("error reopening index %s after updating its settings, it is closed: %+v", name, openErr)
+			}
+		}
+	}()
+
+	if err != nil {
+		return fmt.Errorf("error closing index %s: %+v", name, err)
+	}
+	if err := putIndexSettings(ctx, esClient, name, settings); err != nil {
+		return fmt.Errorf("error updating the settings of closed index %s: %+v", name, err)
+	}
+	return nil
+}
+
+func openIndex(ctx context.Context, esClient interface{}, name string, timeout time.Duration) error {
+	log.Printf("[INFO] Reopening index %s", name)
+	waitTimeout := fmt.Sprintf("%ds", int(timeout.Seconds()))
+
 	var (
-		name = d.Id()
-		ctx  = context.Background()
-		err  error
+		timedOut bool
+		err      error
 	)
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.OpenIndex(name).Do(ctx)
+		if err == nil {
+			var health *elastic7.ClusterHealthResponse
+			health, err = client.ClusterHealth().Index(name).WaitForYellowStatus().Timeout(waitTimeout).Do(ctx)
+			timedOut = health != nil && health.TimedOut
+		}
+	case *elastic6.Client:
+		_, err = client.OpenIndex(name).Do(ctx)
+		if err == nil {
+			var health *elastic6.ClusterHealthResponse
+			health, err = client.ClusterHealth().Index(name).WaitForYellowStatus().Timeout(waitTimeout).Do(ctx)
+			timedOut = health != nil && health.TimedOut
+		}
+	default:
+		err = errors.New("Elasticsearch version not supported")
+	}
+	if err != nil {
+		return err
+	}
+	if timedOut {
+		return fmt.Errorf("timed out after %s waiting for the shards of index %s to be active", waitTimeout, name)
+	}
+	return nil
+}
 
-	if alias, ok := d.GetOk("rollover_alias"); ok {
-		name = getWriteIndexByAlias(alias.(string), d, meta)
+// jsonSettingsAttributes are the attributes holding JSON objects of settings,
+// and the settings they set.
+var jsonSettingsAttributes = map[string]string{
+	"index_similarity_default": "index.similarity.default",
+	"analysis_analyzer":        "analysis.analyzer",
+	"analysis_tokenizer":       "analysis.tokenizer",
+	"analysis_filter":          "analysis.filter",
+	"analysis_char_filter":     "analysis.char_filter",
+	"analysis_normalizer":      "analysis.normalizer",
+}
+
+// flatSettingsUpdate returns the flat settings to update a JSON object of
+// settings from old to new. Settings are merged by the put settings API, so
+// settings which were removed, e.g. of a removed analyzer, are set to null.
+func flatSettingsUpdate(key, oldJSON, newJSON string) (map[string]interface{}, error) {
+	oldObject, err := unmarshalJSONObject(oldJSON)
+	if err != nil {
+		return nil, err
+	}
+	newObject, err := unmarshalJSONObject(newJSON)
+	if err != nil {
+		return nil, err
 	}
 
-	esClient, err := getClient(meta.(*ProviderConf))
+	settings := make(map[string]interface{})
+	for k := range flattenMap(oldObject) {
+		settings[key+"."+k] = nil
+	}
+	for k, v := range flattenMap(newObject) {
+		settings[key+"."+k] = v
+	}
+	return settings, nil
+}
+
+// resourceElasticsearchIndexCustomizeDiff replaces the index when settings
+// which can only be updated on a closed index change, unless it may be closed.
+// It also replaces the index when its mappings change in a way the put mapping
+// API can't apply, if `force_destroy` allows deleting its documents, and
+// otherwise fails the plan with the reason.
+func resourceElasticsearchIndexCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	if !d.Get("allow_close_for_updates").(bool) {
+		for _, attr := range closedIndexAttributes {
+			if closedIndexAttributeChanged(d, attr) {
+				if err := d.ForceNew(attr); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if d.HasChange("settings_raw") && d.NewValueKnown("settings_raw") {
+		o, n := d.GetChange("settings_raw")
+		changed, err := rawSettingsUpdate(o.(string), n.(string))
+		if err != nil {
+			return err
+		}
+		for key := range changed {
+			if isFinalIndexSetting(key) || (isStaticIndexSetting(key) && !d.Get("allow_close_for_updates").(bool)) {
+				log.Printf("[INFO] Replacing index %s, static setting %s changed", d.Id(), key)
+				if err := d.ForceNew("settings_raw"); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+
+	if !d.HasChange("mappings") || !d.NewValueKnown("mappings") {
+		return nil
+	}
+
+	o, n := d.GetChange("mappings")
+	err := incompatibleMappingChange(o.(string), n.(string))
+	if err == nil {
+		return nil
+	}
+	if d.Get("force_destroy").(bool) {
+		log.Printf("[INFO] Replacing index %s, its mappings can't be updated in place: %+v", d.Id(), err)
+		return d.ForceNew("mappings")
+	}
+	return fmt.Errorf("the mappings of index %s can't be updated in place: %+v. Set force_destroy to replace the index, deleting its documents", d.Id(), err)
+}
+
+// closedIndexAttributeChanged returns whether an attribute which can only be
+// updated on a closed index changed. ResourceDiff.HasChange ignores the
+// DiffSuppressFunc of the attribute, so equivalent JSON, e.g. analysis
+// settings read back with values as strings, would otherwise replace the
+// index.
+func closedIndexAttributeChanged(d *schema.ResourceDiff, attr string) bool {
+	if !d.HasChange(attr) {
+		return false
+	}
+	suppress := configSchema[attr].DiffSuppressFunc
+	if suppress == nil || !d.NewValueKnown(attr) {
+		return true
+	}
+	o, n := d.GetChange(attr)
+	return !suppress(attr, fmt.Sprintf("%v", o), fmt.Sprintf("%v", n), nil)
+}
+
+// mappingRootParameters are the parameters allowed at the root of a mapping,
+// any other key is a doc type wrapping the mapping.
+var mappingRootParameters = map[string]bool{
+	"_all":                 true,
+	"_field_names":         true,
+	"_meta":                true,
+	"_routing":             true,
+	"_source":              true,
+	"date_detection":       true,
+	"dynamic":              true,
+	"dynamic_date_formats": true,
+	"dynamic_templates":    true,
+	"enabled":              true,
+	"numeric_detection":    true,
+	"properties":           true,
+	"runtime":              true,
+}
+
+// mappingUpdatableRootParameters are the root parameters the put mapping API
+// replaces, besides `properties` and `runtime` which are merged.
+var mappingUpdatableRootParameters = map[string]bool{
+	"_meta":             true,
+	"date_detection":    true,
+	"dynamic":           true,
+	"dynamic_templates": true,
+	"numeric_detection": true,
+}
+
+// splitMappingType returns the doc type and the mapping it wraps for mappings
+// of Elasticsearch 6 or created with `include_type_name`, or an empty type.
+func splitMappingType(mappings map[string]interface{}) (string, map[string]interface{}) {
+	if len(mappings) != 1 {
+		return "", mappings
+	}
+	for k, v := range mappings {
+		if m, ok := v.(map[string]interface{}); ok && !mappingRootParameters[k] {
+			return k, m
+		}
+	}
+	return "", mappings
+}
+
+// unmarshalJSONObject unmarshals a JSON object attribute, which is empty if
+// unset.
+func unmarshalJSONObject(objectJSON string) (map[string]interface{}, error) {
+	object := map[string]interface{}{}
+	if objectJSON == "" {
+		return object, nil
+	}
+	if err := json.Unmarshal([]byte(objectJSON), &object); err != nil {
+		return nil, fmt.Errorf("fail to unmarshal: %v", err)
+	}
+	return object, nil
+}
+
+// incompatibleMappingChange returns why the mappings can't be updated from old
+// to new with the put mapping API, or nil if the change is purely additive.
+func incompatibleMappingChange(oldJSON, newJSON string) error {
+	oldMappings, err := unmarshalJSONObject(oldJSON)
+	if err != nil {
+		return err
+	}
+	newMappings, err := unmarshalJSONObject(newJSON)
 	if err != nil {
 		return err
 	}
+
+	// Elasticsearch 7 returns typeless mappings even if they were created with
+	// a type, so only an explicit change of type is incompatible
+	oldType, _ := splitMappingType(oldMappings)
+	newType, _ := splitMappingType(newMappings)
+	if oldType != "" && newType != "" && oldType != newType {
+		return fmt.Errorf("the mapping type can't be changed from %q to %q", oldType, newType)
+	}
+
+	oldMapping, newMapping := comparableMappings(oldMappings, newMappings)
+	for _, k := range unionKeys(oldMapping, newMapping) {
+		ov, nv := oldMapping[k], newMapping[k]
+		switch {
+		case k == "properties":
+			if err := compareMappingProperties("", ov, nv); err != nil {
+				return err
+			}
+		case k == "runtime", mappingUpdatableRootParameters[k]:
+			// merged or replaced by the put mapping API
+		case !reflect.DeepEqual(ov, nv):
+			return fmt.Errorf("the mapping parameter %q can't be changed", k)
+		}
+	}
+	return nil
+}
+
+// comparableMappings returns the old and new mappings without doc type and
+// normalized as returned by the get index API. Fields of old which are
+// missing from new are ignored where dynamic mapping is enabled, as indexing
+// documents may have added them. Root parameters of old which are missing
+// from new, e.g. `_meta` or `dynamic_templates` added by an index template,
+// are ignored too, as the put mapping API can't remove them.
+func comparableMappings(oldMappings, newMappings map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	_, oldMapping := splitMappingType(oldMappings)
+	_, newMapping := splitMappingType(newMappings)
+	oldMapping = normalizeMappingValue(oldMapping).(map[string]interface{})
+	newMapping = normalizeMappingValue(newMapping).(map[string]interface{})
+
+	for k := range oldMapping {
+		if _, ok := newMapping[k]; !ok && k != "properties" && k != "runtime" {
+			delete(oldMapping, k)
+		}
+	}
+	pruneDynamicFields(oldMapping, newMapping, true)
+	return oldMapping, newMapping
+}
+
+// normalizeMappingValue returns a copy of a mapping with values converted to
+// strings, e.g. `"index": "false"` and `"index": false` are equivalent, without
+// empty properties and without the default `object` type of fields with
+// properties.
+func normalizeMappingValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, value := range v {
+			m[k] = normalizeMappingValue(value)
+		}
+		if properties, ok := m["properties"].(map[string]interface{}); ok && len(properties) == 0 {
+			delete(m, "properties")
+		}
+		if _, ok := m["properties"]; ok && m["type"] == "object" {
+			delete(m, "type")
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, value := range v {
+			l[i] = normalizeMappingValue(value)
+		}
+		return l
+	case nil:
+		return nil
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// pruneDynamicFields removes the fields of old which are missing from new
+// where dynamic mapping is enabled, inheriting `dynamic` from parent objects.
+func pruneDynamicFields(old, new map[string]interface{}, dynamic bool) {
+	if value, ok := new["dynamic"]; ok {
+		dynamic = value == "true"
+	}
+
+	oldFields, _ := old["properties"].(map[string]interface{})
+	newFields, _ := new["properties"].(map[string]interface{})
+	for name, oldField := range oldFields {
+		newField, ok := newFields[name]
+		if !ok {
+			if dynamic {
+				delete(oldFields, name)
+			}
+			continue
+		}
+		oldFieldMap, _ := oldField.(map[string]interface{})
+		newFieldMap, _ := newField.(map[string]interface{})
+		if oldFieldMap != nil && newFieldMap != nil {
+			pruneDynamicFields(oldFieldMap, newFieldMap, dynamic)
+		}
+	}
+	if oldFields != nil && len(oldFields) == 0 {
+		delete(old, "properties")
+	}
+}
+
+// compareMappingProperties checks the fields of properties or multi-fields,
+// new fields can be added but existing ones can't be removed.
+func compareMappingProperties(prefix string, old, new interface{}) error {
+	oldFields, _ := old.(map[string]interface{})
+	newFields, _ := new.(map[string]interface{})
+
+	for _, name := range unionKeys(oldFields, nil) {
+		path := prefix + name
+		nf, ok := newFields[name]
+		if !ok {
+			return fmt.Errorf("field %q was removed, fields can't be removed from a mapping", path)
+		}
+		if err := compareMappingField(path, oldFields[name], nf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func compareMappingField(path string, old, new interface{}) error {
+	oldField, _ := old.(map[string]interface{})
+	newField, _ := new.(map[string]interface{})
+
+	for _, k := range unionKeys(oldField, newField) {
+		ov, nv := oldField[k], newField[k]
+		switch k {
+		case "properties", "fields":
+			if err := compareMappingProperties(path+".", ov, nv); err != nil {
+				return err
+			}
+		case "type":
+			oldType, newType := mappingFieldType(oldField), mappingFieldType(newField)
+			if oldType != newType {
+				return fmt.Errorf("the type of field %q can't be changed from %q to %q", path, oldType, newType)
+			}
+		case "ignore_above":
+			if mappingNumber(nv) < mappingNumber(ov) {
+				return fmt.Errorf("ignore_above of field %q can only be raised, from %v", path, ov)
+			}
+		default:
+			if !reflect.DeepEqual(ov, nv) {
+				return fmt.Errorf("parameter %q of field %q can't be changed", k, path)
+			}
+		}
+	}
+	return nil
+}
+
+// mappingFieldType returns the type of a field, fields with properties and no
+// type are objects.
+func mappingFieldType(field map[string]interface{}) string {
+	if t, ok := field["type"].(string); ok {
+		return t
+	}
+	return "object"
+}
+
+// mappingNumber returns a numeric mapping parameter, or the maximum value if
+// it isn't set, e.g. for the default of `ignore_above`.
+func mappingNumber(v interface{}) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return math.MaxInt32
+}
+
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// updateIndexMappings applies an additive mappings change with the put
+// mapping API. Runtime fields removed from the mappings are removed from the
+// index by setting them to null.
+func updateIndexMappings(ctx context.Context, esClient interface{}, name, oldJSON, newJSON string) error {
+	oldMappings, err := unmarshalJSONObject(oldJSON)
+	if err != nil {
+		return err
+	}
+	newMappings, err := unmarshalJSONObject(newJSON)
+	if err != nil {
+		return err
+	}
+
+	_, oldMapping := splitMappingType(oldMappings)
+	typ, body := splitMappingType(newMappings)
+	if oldRuntime, ok := oldMapping["runtime"].(map[string]interface{}); ok {
+		runtime, _ := body["runtime"].(map[string]interface{})
+		for field := range oldRuntime {
+			if _, ok := runtime[field]; !ok {
+				if runtime == nil {
+					runtime = map[string]interface{}{}
+					body["runtime"] = runtime
+				}
+				runtime[field] = nil
+			}
+		}
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.IndexPutSettings(name).BodyJson(body).Do(ctx)
+		if typ != "" {
+			// the put mapping service of the v7 client doesn't support types
+			_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
+				Method: "PUT",
+				Path:   fmt.Sprintf("/%s/_mapping/%s", url.PathEscape(name), url.PathEscape(typ)),
+				Params: url.Values{"include_type_name": []string{"true"}},
+				Body:   body,
+			})
+		} else {
+			_, err = client.PutMapping().Index(name).BodyJson(body).Do(ctx)
+		}
 	case *elastic6.Client:
-		_, err = client.IndexPutSettings(name).BodyJson(body).Do(ctx)
+		if typ != "" {
+			_, err = client.PutMapping().Index(name).Type(typ).BodyJson(body).Do(ctx)
+		} else {
+			_, err = client.PutMapping().Index(name).IncludeTypeName(false).BodyJson(body).Do(ctx)
+		}
 	default:
-		return errors.New("Elasticsearch version not supported")
+		err = errors.New("Elasticsearch version not supported")
+	}
+	if err != nil {
+		return fmt.Errorf("error updating the mappings of index %s: %+v", name, err)
 	}
+	return nil
+}
 
-	if err == nil {
-		return resourceElasticsearchIndexRead(d, meta.(*ProviderConf))
+// updateIndexAliases applies the changes between the old and new aliases of
+// an index in a single request to the aliases API, so they are atomic. Changed
+// aliases are added again, which replaces their filter and routing.
+func updateIndexAliases(ctx context.Context, esClient interface{}, name, oldJSON, newJSON string) error {
+	oldAliases := map[string]interface{}{}
+	newAliases := map[string]interface{}{}
+	if oldJSON != "" {
+		if err := json.Unmarshal([]byte(oldJSON), &oldAliases); err != nil {
+			return fmt.Errorf("fail to unmarshal: %v", err)
+		}
 	}
-	return err
+	if newJSON != "" {
+		if err := json.Unmarshal([]byte(newJSON), &newAliases); err != nil {
+			return fmt.Errorf("fail to unmarshal: %v", err)
+		}
+	}
+	normalizeIndexAliases(oldAliases)
+	normalizeIndexAliases(newAliases)
+
+	var actions []map[string]interface{}
+	for _, alias := range unionKeys(oldAliases, newAliases) {
+		oldAlias, inOld := oldAliases[alias]
+		newAlias, inNew := newAliases[alias]
+		switch {
+		case !inNew:
+			actions = append(actions, map[string]interface{}{
+				"remove": map[string]interface{}{"index": name, "alias": alias},
+			})
+		case !inOld || !reflect.DeepEqual(oldAlias, newAlias):
+			add := map[string]interface{}{"index": name, "alias": alias}
+			for k, v := range newAlias.(map[string]interface{}) {
+				add[k] = v
+			}
+			actions = append(actions, map[string]interface{}{"add": add})
+		}
+	}
+	if len(actions) == 0 {
+		return nil
+	}
+
+	body := map[string]interface{}{"actions": actions}
+	var err error
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_aliases",
+			Body:   body,
+		})
+	case *elastic6.Client:
+		_, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_aliases",
+			Body:   body,
+		})
+	default:
+		err = errors.New("Elasticsearch version not supported")
+	}
+	if err != nil {
+		return fmt.Errorf("error updating the aliases of index %s: %+v", name, err)
+	}
+	return nil
+}
+
+// indexAliasesJSON returns the aliases of an index as returned by the get
+// index API as JSON, or an empty string if it has none.
+func indexAliasesJSON(aliases map[string]interface{}) (string, error) {
+	if len(aliases) == 0 {
+		return "", nil
+	}
+	normalizeIndexAliases(aliases)
+
+	aliasesJSON, err := json.Marshal(aliases)
+	if err != nil {
+		return "", err
+	}
+	return string(aliasesJSON), nil
+}
+
+// indexMappingsJSON returns the mappings of an index as returned by the get
+// index API as JSON, or an empty string if it has none.
+func indexMappingsJSON(mappings map[string]interface{}) (string, error) {
+	if len(mappings) == 0 {
+		return "", nil
+	}
+
+	mappingsJSON, err := json.Marshal(mappings)
+	if err != nil {
+		return "", err
+	}
+	return string(mappingsJSON), nil
+}
+
+// indexSettingsObjectJSON returns the flat settings under key, e.g. the
+// analyzers under `index.analysis.analyzer`, as a JSON object, or an empty
+// string if there are none. Analysis components are keyed by their name,
+// which may contain dots, and their parameters don't, so only the last part
+// of their settings is nested.
+func indexSettingsObjectJSON(settings map[string]interface{}, key string) (string, error) {
+	prefix := indexSettingKey(key) + "."
+	analysis := strings.HasPrefix(key, "analysis.")
+	object := make(map[string]interface{})
+	for k, v := range settings {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		k = strings.TrimPrefix(k, prefix)
+		if i := strings.LastIndex(k, "."); analysis && i > 0 {
+			component, ok := object[k[:i]].(map[string]interface{})
+			if !ok {
+				component = make(map[string]interface{})
+				object[k[:i]] = component
+			}
+			component[k[i+1:]] = v
+			continue
+		}
+		object[k] = v
+	}
+	if len(object) == 0 {
+		return "", nil
+	}
+	if !analysis {
+		object = unflattenMap(object)
+	}
+
+	objectJSON, err := json.Marshal(object)
+	if err != nil {
+		return "", err
+	}
+	return string(objectJSON), nil
 }
 
-func getWriteIndexByAlias(alias string, d *schema.ResourceData, meta interface{}) string {
+func getWriteIndexByAlias(ctx context.Context, alias string, d *schema.ResourceData, meta interface{}) string {
 	var (
 		index   = d.Id()
-		ctx     = context.Background()
 		columns = []string{"index", "is_write_index"}
 	)
 
@@ -762,25 +1629,26 @@ func getWriteIndexByAlias(alias string, d *schema.ResourceData, meta interface{}
 	return index
 }
 
-func resourceElasticsearchIndexRead(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchIndexRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	var (
 		index    = d.Id()
-		ctx      = context.Background()
 		settings map[string]interface{}
+		mappings map[string]interface{}
+		aliases  map[string]interface{}
 	)
 
 	if alias, ok := d.GetOk("rollover_alias"); ok {
-		index = getWriteIndexByAlias(alias.(string), d, meta)
+		index = getWriteIndexByAlias(ctx, alias.(string), d, meta)
 	}
 
 	// The logic is repeated strictly because of the types
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		r, err := client.IndexGetSettings(index).FlatSettings(true).Do(ctx)
+		r, err := client.IndexGet(index).Do(ctx)
 		if err != nil {
 			if elastic7.IsNotFound(err) {
 				log.Printf("[WARN] Index (%s) not found, removing from state", index)
@@ -788,39 +1656,45 @@ func resourceElasticsearchIndexRead(d *schema.ResourceData, meta interface{}) er
 				return nil
 			}
 
-			return err
+			return diag.FromErr(err)
 		}
 
 		if resp, ok := r[index]; ok {
-			settings = resp.Settings
+			settings = flattenMap(resp.Settings)
+			mappings = resp.Mappings
+			aliases = resp.Aliases
 		}
 	case *elastic6.Client:
-		r, err := client.IndexGetSettings(index).FlatSettings(true).Do(ctx)
+		r, err := client.IndexGet(index).Do(ctx)
 		if err != nil {
 			if elastic6.IsNotFound(err) {
 				log.Printf("[WARN] Index (%s) not found, removing from state", index)
 				d.SetId("")
 				return nil
 			}
-			return err
+			return diag.FromErr(err)
 		}
 
 		if resp, ok := r[index]; ok {
-			settings = resp.Settings
+			settings = flattenMap(resp.Settings)
+			mappings = resp.Mappings
+			aliases = resp.Aliases
 		}
 	default:
-		return errors.New("Elasticsearch version not supported")
+		return diag.FromErr(errors.New("Elasticsearch version not supported"))
 	}
 
 	// Don't override name otherwise it will force a replacement
-	if _, ok := d.GetOk("name"); !ok {
+	_, hasName := d.GetOk("name")
+	importing := !hasName
+	if importing {
 		name := index
 		if providedName, ok := settings["index.provided_name"].(string); ok {
 			name = providedName
 		}
 		err := d.Set("name", name)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -828,21 +1702,60 @@ func resourceElasticsearchIndexRead(d *schema.ResourceData, meta interface{}) er
 	if alias, ok := settings["index.lifecycle.rollover_alias"].(string); ok {
 		err := d.Set("rollover_alias", alias)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	} else if alias, ok := settings["index.opendistro.index_state_management.rollover_alias"].(string); ok {
 		err := d.Set("rollover_alias", alias)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	} else if alias, ok := settings["plugins.index_state_management.rollover_alias"].(string); ok {
 		err := d.Set("rollover_alias", alias)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
 	indexResourceDataFromSettings(settings, d)
 
+	settingsRaw, err := indexSettingsRawFromSettings(settings, d.Get("settings_raw").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("settings_raw", settingsRaw); err != nil {
+		return diag.FromErr(err)
+	}
+
+	aliasesJSON, err := indexAliasesJSON(aliases)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	mappingsJSON, err := indexMappingsJSON(mappings)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// aliases, mappings and analysis settings are only read back when they're
+	// managed, or on import, so the ones added by index templates don't show
+	// as drift
+	ds := &resourceDataSetter{d: d}
+	setManaged := func(attr, value string) {
+		if importing || d.Get(attr).(string) != "" {
+			ds.set(attr, value)
+		}
+	}
+	setManaged("aliases", aliasesJSON)
+	setManaged("mappings", mappingsJSON)
+	for attr, key := range jsonSettingsAttributes {
+		objectJSON, err := indexSettingsObjectJSON(settings, key)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		setManaged(attr, objectJSON)
+	}
+	if ds.err != nil {
+		return diag.FromErr(ds.err)
+	}
+
 	return nil
 }
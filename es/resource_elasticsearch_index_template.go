@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"log"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	elastic7 "github.com/olivere/elastic/v7"
@@ -14,10 +16,10 @@ import (
 
 func resourceElasticsearchIndexTemplate() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchIndexTemplateCreate,
-		Read:   resourceElasticsearchIndexTemplateRead,
-		Update: resourceElasticsearchIndexTemplateUpdate,
-		Delete: resourceElasticsearchIndexTemplateDelete,
+		CreateContext: resourceElasticsearchIndexTemplateCreate,
+		ReadContext:   resourceElasticsearchIndexTemplateRead,
+		UpdateContext: resourceElasticsearchIndexTemplateUpdate,
+		DeleteContext: resourceElasticsearchIndexTemplateDelete,
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -34,34 +36,40 @@ func resourceElasticsearchIndexTemplate() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchIndexTemplateCreate(d *schema.ResourceData, meta interface{}) error {
-	err := resourceElasticsearchPutIndexTemplate(d, meta, true)
+func resourceElasticsearchIndexTemplateCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	err := resourceElasticsearchPutIndexTemplate(ctx, d, meta, true)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId(d.Get("name").(string))
 	return nil
 }
 
-func resourceElasticsearchIndexTemplateRead(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchIndexTemplateRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
 	var result string
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		result, err = elastic7IndexGetTemplate(client, id)
+		result, err = elastic7IndexGetTemplate(ctx, client, id)
 	case *elastic6.Client:
-		result, err = elastic6IndexGetTemplate(client, id)
+		result, err = elastic6IndexGetTemplate(ctx, client, id)
 	default:
-		return errors.New("Elasticsearch version not supported")
+		return diag.FromErr(errors.New("Elasticsearch version not supported"))
 	}
 	if err != nil {
 		if elastic7.IsNotFound(err) || elastic6.IsNotFound(err) {
@@ -70,17 +78,17 @@ func resourceElasticsearchIndexTemplateRead(d *schema.ResourceData, meta interfa
 			return nil
 		}
 
-		return err
+		return diag.FromErr(err)
 	}
 
 	ds := &resourceDataSetter{d: d}
 	ds.set("name", d.Id())
 	ds.set("body", result)
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func elastic7IndexGetTemplate(client *elastic7.Client, id string) (string, error) {
-	res, err := client.IndexGetTemplate(id).Do(context.TODO())
+func elastic7IndexGetTemplate(ctx context.Context, client *elastic7.Client, id string) (string, error) {
+	res, err := client.IndexGetTemplate(id).Do(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -93,8 +101,8 @@ func elastic7IndexGetTemplate(client *elastic7.Client, id string) (string, error
 	return string(tj), nil
 }
 
-func elastic6IndexGetTemplate(client *elastic6.Client, id string) (string, error) {
-	res, err := client.IndexGetTemplate(id).Do(context.TODO())
+func elastic6IndexGetTemplate(ctx context.Context, client *elastic6.Client, id string) (string, error) {
+	res, err := client.IndexGetTemplate(id).Do(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -107,45 +115,45 @@ func elastic6IndexGetTemplate(client *elastic6.Client, id string) (string, error
 	return string(tj), nil
 }
 
-func resourceElasticsearchIndexTemplateUpdate(d *schema.ResourceData, meta interface{}) error {
-	return resourceElasticsearchPutIndexTemplate(d, meta, false)
+func resourceElasticsearchIndexTemplateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return diag.FromErr(resourceElasticsearchPutIndexTemplate(ctx, d, meta, false))
 }
 
-func resourceElasticsearchIndexTemplateDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchIndexTemplateDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		err = elastic7IndexDeleteTemplate(client, id)
+		err = elastic7IndexDeleteTemplate(ctx, client, id)
 	case *elastic6.Client:
-		err = elastic6IndexDeleteTemplate(client, id)
+		err = elastic6IndexDeleteTemplate(ctx, client, id)
 	default:
-		return errors.New("Elasticsearch version not supported")
+		return diag.FromErr(errors.New("Elasticsearch version not supported"))
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId("")
 	return nil
 }
 
-func elastic7IndexDeleteTemplate(client *elastic7.Client, id string) error {
-	_, err := client.IndexDeleteTemplate(id).Do(context.TODO())
+func elastic7IndexDeleteTemplate(ctx context.Context, client *elastic7.Client, id string) error {
+	_, err := client.IndexDeleteTemplate(id).Do(ctx)
 	return err
 }
 
-func elastic6IndexDeleteTemplate(client *elastic6.Client, id string) error {
-	_, err := client.IndexDeleteTemplate(id).Do(context.TODO())
+func elastic6IndexDeleteTemplate(ctx context.Context, client *elastic6.Client, id string) error {
+	_, err := client.IndexDeleteTemplate(id).Do(ctx)
 	return err
 }
 
-func resourceElasticsearchPutIndexTemplate(d *schema.ResourceData, meta interface{}, create bool) error {
+func resourceElasticsearchPutIndexTemplate(ctx context.Context, d *schema.ResourceData, meta interface{}, create bool) error {
 	name := d.Get("name").(string)
 	body := d.Get("body").(string)
 
@@ -156,9 +164,9 @@ func resourceElasticsearchPutIndexTemplate(d *schema.ResourceData, meta interfac
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		err = elastic7IndexPutTemplate(client, name, body, create)
+		err = elastic7IndexPutTemplate(ctx, client, name, body, create)
 	case *elastic6.Client:
-		err = elastic6IndexPutTemplate(client, name, body, create)
+		err = elastic6IndexPutTemplate(ctx, client, name, body, create)
 	default:
 		return errors.New("Elasticsearch version not supported")
 	}
@@ -166,12 +174,12 @@ func resourceElasticsearchPutIndexTemplate(d *schema.ResourceData, meta interfac
 	return err
 }
 
-func elastic7IndexPutTemplate(client *elastic7.Client, name string, body string, create bool) error {
-	_, err := client.IndexPutTemplate(name).BodyString(body).Create(create).Do(context.TODO())
+func elastic7IndexPutTemplate(ctx context.Context, client *elastic7.Client, name string, body string, create bool) error {
+	_, err := client.IndexPutTemplate(name).BodyString(body).Create(create).Do(ctx)
 	return err
 }
 
-func elastic6IndexPutTemplate(client *elastic6.Client, name string, body string, create bool) error {
-	_, err := client.IndexPutTemplate(name).BodyString(body).Create(create).Do(context.TODO())
+func elastic6IndexPutTemplate(ctx context.Context, client *elastic6.Client, name string, body string, create bool) error {
+	_, err := client.IndexPutTemplate(name).BodyString(body).Create(create).Do(ctx)
 	return err
 }
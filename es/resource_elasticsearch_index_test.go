@@ -2,10 +2,17 @@ package es
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -93,6 +100,36 @@ resource "elasticsearch_index" "test" {
     }
   })
 }
+`
+	testAccElasticsearchIndexAnalysisUpdate = `
+resource "elasticsearch_index" "test" {
+  name                    = "terraform-test"
+  number_of_shards        = 1
+  number_of_replicas      = 1
+  allow_close_for_updates = true
+  analysis_analyzer = jsonencode({
+    default = {
+      filter = [
+        "lowercase",
+      ]
+      tokenizer = "standard"
+    }
+    full_text_search = {
+      filter = [
+        "lowercase",
+        "asciifolding",
+      ]
+      tokenizer = "custom_ngram_tokenizer"
+    }
+  })
+  analysis_tokenizer = jsonencode({
+    custom_ngram_tokenizer = {
+      max_gram = "3"
+      min_gram = "2"
+      type     = "ngram"
+    }
+  })
+}
 `
 	testAccElasticsearchIndexInvalid = `
 resource "elasticsearch_index" "test" {
@@ -148,6 +185,117 @@ resource "elasticsearch_index" "test_doctype" {
 }
 EOF
 }
+`
+	testAccElasticsearchIndexMappings = `
+resource "elasticsearch_index" "test" {
+  name               = "terraform-test"
+  number_of_shards   = 1
+  number_of_replicas = 1
+  mappings = jsonencode({
+    properties = {
+      name = {
+        type = "keyword"
+      }
+    }
+  })
+}
+`
+	testAccElasticsearchIndexMappingsAdditive = `
+resource "elasticsearch_index" "test" {
+  name               = "terraform-test"
+  number_of_shards   = 1
+  number_of_replicas = 1
+  mappings = jsonencode({
+    properties = {
+      name = {
+        type = "keyword"
+        fields = {
+          text = {
+            type = "text"
+          }
+        }
+      }
+      age = {
+        type = "integer"
+      }
+    }
+  })
+}
+`
+	testAccElasticsearchIndexMappingsIncompatible = `
+resource "elasticsearch_index" "test" {
+  name               = "terraform-test"
+  number_of_shards   = 1
+  number_of_replicas = 1
+  mappings = jsonencode({
+    properties = {
+      name = {
+        type = "text"
+      }
+      age = {
+        type = "integer"
+      }
+    }
+  })
+}
+`
+	testAccElasticsearchIndexAliases = `
+resource "elasticsearch_index" "test" {
+  name               = "terraform-test"
+  number_of_shards   = 1
+  number_of_replicas = 1
+  aliases = jsonencode({
+    "terraform-test-read"  = {}
+    "terraform-test-stale" = {}
+  })
+}
+`
+	testAccElasticsearchIndexAliasesUpdate = `
+resource "elasticsearch_index" "test" {
+  name               = "terraform-test"
+  number_of_shards   = 1
+  number_of_replicas = 1
+  aliases = jsonencode({
+    "terraform-test-read" = {
+      routing = "1"
+      filter = {
+        term = {
+          user = "jane"
+        }
+      }
+    }
+    "terraform-test-write" = {
+      is_write_index = true
+    }
+  })
+}
+`
+	testAccElasticsearchIndexSettingsRaw = `
+resource "elasticsearch_index" "test" {
+  name               = "terraform-test"
+  number_of_shards   = 1
+  number_of_replicas = 1
+  settings_raw = jsonencode({
+    "index.mapping.total_fields.limit" = 2000
+    "index.translog.durability"        = "async"
+  })
+}
+`
+	testAccElasticsearchIndexSettingsRawUpdate = `
+resource "elasticsearch_index" "test" {
+  name               = "terraform-test"
+  number_of_shards   = 1
+  number_of_replicas = 1
+  settings_raw = jsonencode({
+    index = {
+      mapping = {
+        total_fields = {
+          limit = 5000
+        }
+      }
+    }
+  })
+}
 `
 	testAccElasticsearchIndexUpdateForceDestroy = `
 resource "elasticsearch_index" "test" {
@@ -329,6 +477,111 @@ func TestAccElasticsearchIndexAnalysis(t *testing.T) {
 					checkElasticsearchIndexExists("elasticsearch_index.test"),
 				),
 			},
+			{
+				Config: testAccElasticsearchIndexAnalysisUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "allow_close_for_updates", "true"),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_index.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					// not returned from the API
+					"allow_close_for_updates",
+					"force_destroy",
+				},
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_mappingsUpdate(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexMappings,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexMappingsAdditive,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexMappingField("elasticsearch_index.test", "age"),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_index.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					// not returned from the API
+					"force_destroy",
+				},
+			},
+			{
+				Config:      testAccElasticsearchIndexMappingsIncompatible,
+				ExpectError: regexp.MustCompile(`the type of field "name" can't be changed from "keyword" to "text"`),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_aliasesUpdate(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexAliases,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexRolloverAliasExists(testAccProvider, "terraform-test-stale"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexAliasesUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexRolloverAliasExists(testAccProvider, "terraform-test-write"),
+					checkElasticsearchIndexRolloverAliasDestroy(testAccProvider, "terraform-test-stale"),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_index.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"force_destroy", // not returned from the API
+				},
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_settingsRaw(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexSettingsRaw,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexSettingsRawUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "settings_raw", `{"index.mapping.total_fields.limit":"5000"}`),
+				),
+			},
 		},
 	})
 }
@@ -459,7 +712,6 @@ func TestAccElasticsearchIndex_rolloverAliasXpack(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 				ImportStateVerifyIgnore: []string{
-					"aliases",       // not handled by this provider
 					"force_destroy", // not returned from the API
 				},
 				ImportStateCheck: checkElasticsearchIndexRolloverAliasState("terraform-test"),
@@ -509,7 +761,6 @@ func TestAccElasticsearchIndex_rolloverAliasOpendistro(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 				ImportStateVerifyIgnore: []string{
-					"aliases",       // not handled by this provider
 					"force_destroy", // not returned from the API
 				},
 				ImportStateCheck: checkElasticsearchIndexRolloverAliasState("terraform-test"),
@@ -597,6 +848,45 @@ func checkElasticsearchIndexUpdated(name string) resource.TestCheckFunc {
 	}
 }
 
+func checkElasticsearchIndexMappingField(name string, field string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("not found: %s", name)
+		}
+
+		meta := testAccProvider.Meta()
+
+		var mappings map[string]interface{}
+		esClient, err := getClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			resp, err := client.GetMapping().Index(rs.Primary.ID).Do(context.TODO())
+			if err != nil {
+				return err
+			}
+			mappings, _ = resp[rs.Primary.ID].(map[string]interface{})["mappings"].(map[string]interface{})
+		case *elastic6.Client:
+			resp, err := client.GetMapping().Index(rs.Primary.ID).IncludeTypeName(false).Do(context.TODO())
+			if err != nil {
+				return err
+			}
+			mappings, _ = resp[rs.Primary.ID].(map[string]interface{})["mappings"].(map[string]interface{})
+		default:
+			return errors.New("Elasticsearch version not supported")
+		}
+
+		properties, _ := mappings["properties"].(map[string]interface{})
+		if _, ok := properties[field]; !ok {
+			return fmt.Errorf("field %q not found in mappings %v", field, mappings)
+		}
+		return nil
+	}
+}
+
 func checkElasticsearchIndexDestroy(s *terraform.State) error {
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "elasticsearch_index" {
@@ -710,3 +1000,509 @@ func checkElasticsearchIndexRolloverAliasDestroy(provider *schema.Provider, alia
 		return nil
 	}
 }
+
+func TestIncompatibleMappingChange(t *testing.T) {
+	base := `{"properties": {"name": {"type": "keyword", "ignore_above": 256}, "address": {"properties": {"city": {"type": "text"}}}}}`
+	cases := []struct {
+		name     string
+		old, new string
+		err      string
+	}{
+		{"unchanged", base, base, ""},
+		{"no mappings", "", base, ""},
+		{"new field", base, `{"properties": {"name": {"type": "keyword", "ignore_above": 256}, "age": {"type": "integer"}, "address": {"properties": {"city": {"type": "text"}}}}}`, ""},
+		{"new nested field", base, `{"properties": {"name": {"type": "keyword", "ignore_above": 256}, "address": {"properties": {"city": {"type": "text"}, "zip": {"type": "keyword"}}}}}`, ""},
+		{"new multi-field", base, `{"properties": {"name": {"type": "keyword", "ignore_above": 256, "fields": {"text": {"type": "text"}}}, "address": {"properties": {"city": {"type": "text"}}}}}`, ""},
+		{"raised ignore_above", base, `{"properties": {"name": {"type": "keyword", "ignore_above": 1024}, "address": {"properties": {"city": {"type": "text"}}}}}`, ""},
+		{"runtime field", base, `{"runtime": {"day": {"type": "keyword"}}, "properties": {"name": {"type": "keyword", "ignore_above": 256}, "address": {"properties": {"city": {"type": "text"}}}}}`, ""},
+		{"dynamic", base, `{"dynamic": "strict", "properties": {"name": {"type": "keyword", "ignore_above": 256}, "address": {"properties": {"city": {"type": "text"}}}}}`, ""},
+		{"lowered ignore_above", base, `{"properties": {"name": {"type": "keyword", "ignore_above": 128}, "address": {"properties": {"city": {"type": "text"}}}}}`, `ignore_above of field "name" can only be raised, from 256`},
+		{"removed ignore_above", base, `{"properties": {"name": {"type": "keyword"}, "address": {"properties": {"city": {"type": "text"}}}}}`, ""},
+		{"missing dynamic field", base, `{"properties": {"name": {"type": "keyword", "ignore_above": 256}}}`, ""},
+		{"removed field", `{"dynamic": "strict", "properties": {"name": {"type": "keyword"}, "age": {"type": "integer"}}}`, `{"dynamic": "strict", "properties": {"name": {"type": "keyword"}}}`, `field "age" was removed, fields can't be removed from a mapping`},
+		{"removed nested field", `{"properties": {"address": {"dynamic": false, "properties": {"city": {"type": "text"}, "zip": {"type": "keyword"}}}}}`, `{"properties": {"address": {"dynamic": "false", "properties": {"city": {"type": "text"}}}}}`, `field "address.zip" was removed, fields can't be removed from a mapping`},
+		{"equivalent values", `{"properties": {"name": {"type": "keyword", "index": false}, "address": {"properties": {"city": {"type": "text"}}}}}`, `{"properties": {"name": {"type": "keyword", "index": "false"}, "address": {"type": "object", "properties": {"city": {"type": "text"}}}}}`, ""},
+		{"typeless read back", `{"properties": {"name": {"type": "keyword"}}}`, `{"_doc": {"properties": {"name": {"type": "keyword"}, "age": {"type": "long"}}}}`, ""},
+		{"template root parameters", `{"_meta": {"team": "search"}, "_source": {"enabled": false}, "dynamic_templates": [{"strings": {"match_mapping_type": "string", "mapping": {"type": "keyword"}}}], "properties": {"name": {"type": "keyword", "ignore_above": 256}, "address": {"properties": {"city": {"type": "text"}}}}}`, base, ""},
+		{"changed type", base, `{"properties": {"name": {"type": "keyword", "ignore_above": 256}, "address": {"properties": {"city": {"type": "keyword"}}}}}`, `the type of field "address.city" can't be changed from "text" to "keyword"`},
+		{"changed parameter", base, `{"properties": {"name": {"type": "keyword", "ignore_above": 256, "index": false}, "address": {"properties": {"city": {"type": "text"}}}}}`, `parameter "index" of field "name" can't be changed`},
+		{"changed source", base, `{"_source": {"enabled": false}, "properties": {"name": {"type": "keyword", "ignore_above": 256}, "address": {"properties": {"city": {"type": "text"}}}}}`, `the mapping parameter "_source" can't be changed`},
+		{"doc type", `{"_doc": {"properties": {"name": {"type": "keyword"}}}}`, `{"_doc": {"properties": {"name": {"type": "keyword"}, "age": {"type": "long"}}}}`, ""},
+		{"changed doc type", `{"_doc": {"properties": {"name": {"type": "keyword"}}}}`, `{"doc": {"properties": {"name": {"type": "keyword"}}}}`, `the mapping type can't be changed from "_doc" to "doc"`},
+	}
+
+	for _, c := range cases {
+		err := incompatibleMappingChange(c.old, c.new)
+		if c.err == "" && err != nil {
+			t.Errorf("%s: expected no error, got %v", c.name, err)
+		}
+		if c.err != "" && (err == nil || err.Error() != c.err) {
+			t.Errorf("%s: expected error %q, got %v", c.name, c.err, err)
+		}
+	}
+}
+
+func TestUpdateIndexMappings(t *testing.T) {
+	var requests []string
+	var bodies []map[string]interface{}
+	_, esClient := newTestIndexClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		_, _ = w.Write([]byte(`{"acknowledged": true}`))
+	})
+
+	old := `{"runtime": {"day": {"type": "keyword"}}, "properties": {"name": {"type": "keyword"}}}`
+	new := `{"properties": {"name": {"type": "keyword"}, "age": {"type": "long"}}}`
+	if err := updateIndexMappings(context.TODO(), esClient, "my-index", old, new); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	typed := `{"_doc": {"properties": {"name": {"type": "keyword"}}}}`
+	if err := updateIndexMappings(context.TODO(), esClient, "my-index", typed, typed); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	expectedRequests := []string{"/my-index/_mapping", "/my-index/_mapping/_doc?include_type_name=true"}
+	if !reflect.DeepEqual(requests, expectedRequests) {
+		t.Errorf("expected requests %v, got %v", expectedRequests, requests)
+	}
+	expectedBodies := []map[string]interface{}{
+		{
+			"runtime":    map[string]interface{}{"day": nil},
+			"properties": map[string]interface{}{"name": map[string]interface{}{"type": "keyword"}, "age": map[string]interface{}{"type": "long"}},
+		},
+		{
+			"properties": map[string]interface{}{"name": map[string]interface{}{"type": "keyword"}},
+		},
+	}
+	if !reflect.DeepEqual(bodies, expectedBodies) {
+		t.Errorf("expected bodies %v, got %v", expectedBodies, bodies)
+	}
+}
+
+func TestIndexAliases(t *testing.T) {
+	var actions []interface{}
+	_, esClient := newTestIndexClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Actions []interface{} `json:"actions"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		actions = append(actions, body.Actions...)
+		_, _ = w.Write([]byte(`{"acknowledged": true}`))
+	})
+
+	old := `{"read": {"routing": 1}, "stale": {}, "write": {"is_write_index": true}}`
+	new := `{"read": {"routing": "1", "filter": {"term": {"user": "jane"}}}, "write": {"is_write_index": true}, "search": {}}`
+	if err := updateIndexAliases(context.TODO(), esClient, "my-index", old, new); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"add": map[string]interface{}{"index": "my-index", "alias": "read", "filter": map[string]interface{}{"term": map[string]interface{}{"user": "jane"}}, "index_routing": "1", "search_routing": "1"}},
+		map[string]interface{}{"add": map[string]interface{}{"index": "my-index", "alias": "search"}},
+		map[string]interface{}{"remove": map[string]interface{}{"index": "my-index", "alias": "stale"}},
+	}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected actions %v, got %v", expected, actions)
+	}
+
+	// unchanged aliases don't send a request
+	actions = nil
+	if err := updateIndexAliases(context.TODO(), esClient, "my-index", old, old); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(actions) != 0 {
+		t.Errorf("expected no actions, got %v", actions)
+	}
+
+	var indexAliases map[string]interface{}
+	_ = json.Unmarshal([]byte(`{"read": {"filter": {"term": {"user": "jane"}}, "index_routing": "1", "search_routing": "1"}, "write": {"is_write_index": true}}`), &indexAliases)
+	aliases, err := indexAliasesJSON(indexAliases)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	configured := `{"read": {"routing": 1, "filter": {"term": {"user": "jane"}}}, "write": {"is_write_index": true}}`
+	if !diffSuppressIndexAliases("aliases", aliases, configured, nil) {
+		t.Errorf("expected aliases %s to be equivalent to %s", aliases, configured)
+	}
+	if diffSuppressIndexAliases("aliases", aliases, old, nil) {
+		t.Errorf("expected aliases %s to differ from %s", aliases, old)
+	}
+
+	aliases, err = indexAliasesJSON(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if aliases != "" {
+		t.Errorf("expected no aliases, got %s", aliases)
+	}
+	if !diffSuppressIndexAliases("aliases", aliases, "{}", nil) {
+		t.Errorf("expected no aliases to be equivalent to {}")
+	}
+}
+
+func TestPutClosedIndexSettings(t *testing.T) {
+	var requests []string
+	failSettings := false
+	conf, esClient := newTestIndexClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/my-index":
+			_, _ = w.Write([]byte(`{}`))
+		case r.URL.Path == "/my-index/_settings" && failSettings:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": {"type": "illegal_argument_exception", "reason": "unknown tokenizer"}, "status": 400}`))
+		case strings.HasPrefix(r.URL.Path, "/_cluster/health"):
+			_, _ = w.Write([]byte(`{"status": "yellow", "timed_out": false}`))
+		default:
+			_, _ = w.Write([]byte(`{"acknowledged": true}`))
+		}
+	})
+
+	settings := map[string]interface{}{"analysis.analyzer.default.tokenizer": "whitespace"}
+	if err := putClosedIndexSettings(context.TODO(), esClient, "my-index", settings, time.Minute); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := []string{
+		"POST /my-index/_close",
+		"PUT /my-index/_settings",
+		"POST /my-index/_open",
+		"GET /_cluster/health/my-index",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+
+	// the index is reopened when the settings are rejected
+	requests = nil
+	failSettings = true
+	err := putClosedIndexSettings(context.TODO(), esClient, "my-index", settings, time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "unknown tokenizer") {
+		t.Errorf("expected the settings error, got %v", err)
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+
+	// a new analyzer is added before the mapping field using it
+	requests = nil
+	failSettings = false
+	d := schema.TestResourceDataRaw(t, resourceElasticsearchIndex().Schema, map[string]interface{}{
+		"name":                    "my-index",
+		"allow_close_for_updates": true,
+		"analysis_analyzer":       `{"folding": {"tokenizer": "standard", "filter": ["lowercase", "asciifolding"]}}`,
+		"mappings":                `{"properties": {"city": {"type": "text", "analyzer": "folding"}}}`,
+	})
+	d.SetId("my-index")
+	if diags := resourceElasticsearchIndexUpdate(context.TODO(), d, conf); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	expected = []string{
+		"POST /my-index/_close",
+		"PUT /my-index/_settings",
+		"POST /my-index/_open",
+		"GET /_cluster/health/my-index",
+		"PUT /my-index/_mapping",
+		"GET /my-index",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+}
+
+func TestFlatSettingsUpdate(t *testing.T) {
+	old := `{"default": {"tokenizer": "standard", "filter": ["lowercase"]}, "removed": {"tokenizer": "keyword"}}`
+	new := `{"default": {"tokenizer": "whitespace", "filter": ["lowercase"]}}`
+	settings, err := flatSettingsUpdate("analysis.analyzer", old, new)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := map[string]interface{}{
+		"analysis.analyzer.default.tokenizer": "whitespace",
+		"analysis.analyzer.default.filter":    []interface{}{"lowercase"},
+		"analysis.analyzer.removed.tokenizer": nil,
+	}
+	if !reflect.DeepEqual(settings, expected) {
+		t.Errorf("expected settings %v, got %v", expected, settings)
+	}
+}
+
+func TestResourceElasticsearchIndexDiff(t *testing.T) {
+	state := `{"ng":{"max_gram":"3","min_gram":"2","type":"ngram"}}`
+	tests := []struct {
+		name        string
+		config      map[string]interface{}
+		changed     bool
+		requiresNew bool
+	}{
+		{
+			name:   "equivalent",
+			config: map[string]interface{}{"analysis_tokenizer": `{"ng": {"type": "ngram", "min_gram": 2, "max_gram": 3}}`},
+		},
+		{
+			name:        "changed",
+			config:      map[string]interface{}{"analysis_tokenizer": `{"ng": {"type": "ngram", "min_gram": 2, "max_gram": 4}}`},
+			changed:     true,
+			requiresNew: true,
+		},
+		{
+			name:    "changed closing the index",
+			config:  map[string]interface{}{"analysis_tokenizer": `{"ng": {"type": "ngram", "min_gram": 2, "max_gram": 4}}`, "allow_close_for_updates": true},
+			changed: true,
+		},
+	}
+
+	r := resourceElasticsearchIndex()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &terraform.InstanceState{
+				ID: "my-index",
+				Attributes: map[string]string{
+					"id":                      "my-index",
+					"name":                    "my-index",
+					"force_destroy":           "false",
+					"allow_close_for_updates": "false",
+					"include_type_name":       "",
+					"analysis_tokenizer":      state,
+				},
+			}
+			config := map[string]interface{}{"name": "my-index"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+
+			diff, err := r.Diff(context.TODO(), s, terraform.NewResourceConfigRaw(config), nil)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			var attr *terraform.ResourceAttrDiff
+			if diff != nil {
+				attr = diff.Attributes["analysis_tokenizer"]
+			}
+			if changed := attr != nil; changed != tt.changed {
+				t.Fatalf("expected changed %t, got diff %v", tt.changed, diff)
+			}
+			if requiresNew := diff != nil && diff.RequiresNew(); requiresNew != tt.requiresNew {
+				t.Errorf("expected requires new %t, got diff %v", tt.requiresNew, diff)
+			}
+		})
+	}
+}
+
+func TestIndexSettingsRaw(t *testing.T) {
+	old := `{"index": {"lifecycle": {"name": "hot-warm"}, "mapping.total_fields.limit": 2000}, "routing.allocation.require.box_type": "hot"}`
+	new := `{"index.lifecycle.name": "hot-warm", "index.mapping.total_fields.limit": "5000", "index.sort.field": "date"}`
+
+	changed, err := rawSettingsUpdate(old, new)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := map[string]interface{}{
+		"index.mapping.total_fields.limit":          "5000",
+		"index.routing.allocation.require.box_type": nil,
+		"index.sort.field":                          "date",
+	}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Errorf("expected changed settings %v, got %v", expected, changed)
+	}
+
+	static := map[string]bool{
+		"index.mapping.total_fields.limit":          false,
+		"index.routing.allocation.require.box_type": false,
+		"index.knn":                            true,
+		"index.knn.algo_param.ef_search":       false,
+		"index.analysis.analyzer.default.type": true,
+		"index.codec":                          true,
+		"index.sort.field":                     true,
+	}
+	for key, expected := range static {
+		if got := isStaticIndexSetting(key); got != expected {
+			t.Errorf("isStaticIndexSetting(%q): expected %t, got %t", key, expected, got)
+		}
+	}
+	if isFinalIndexSetting("index.codec") || !isFinalIndexSetting("index.sort.field") {
+		t.Errorf("expected only index.sort.field to be final")
+	}
+
+	// only the declared settings are read back
+	settings := map[string]interface{}{
+		"index.lifecycle.name":             "hot-warm",
+		"index.mapping.total_fields.limit": "2000",
+		"index.number_of_shards":           "1",
+		"index.provided_name":              "my-index",
+	}
+	settingsRaw, err := indexSettingsRawFromSettings(settings, old)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if settingsRaw != `{"index.lifecycle.name":"hot-warm","index.mapping.total_fields.limit":"2000"}` {
+		t.Errorf("unexpected settings_raw %s", settingsRaw)
+	}
+	if diffSuppressIndexSettingsRaw("settings_raw", settingsRaw, old, nil) {
+		t.Errorf("expected the removed allocation setting to show as a diff")
+	}
+	if !diffSuppressIndexSettingsRaw("settings_raw", settingsRaw, `{"lifecycle.name": "hot-warm", "index": {"mapping": {"total_fields": {"limit": 2000}}}}`, nil) {
+		t.Errorf("expected equivalent settings to be suppressed")
+	}
+
+	_, errs := validateIndexSettingsRaw(`{"index.lifecycle.name": "hot-warm", "number_of_replicas": 2, "analysis": {"analyzer": {"default": {"type": "simple"}}}}`, "settings_raw")
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if errs[0].Error() != `"settings_raw": index.analysis.analyzer.default.type is managed by the `+"`analysis_analyzer`"+` attribute` {
+		t.Errorf("unexpected error %v", errs[0])
+	}
+	if errs[1].Error() != `"settings_raw": index.number_of_replicas is managed by the `+"`number_of_replicas`"+` attribute` {
+		t.Errorf("unexpected error %v", errs[1])
+	}
+}
+
+func TestResourceElasticsearchIndexRead(t *testing.T) {
+	conf, _ := newTestIndexClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/my-index" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": {"type": "index_not_found_exception"}, "status": 404}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{
+  "my-index": {
+    "aliases": {"search": {"index_routing": "1", "search_routing": "1"}},
+    "mappings": {
+      "_meta": {"team": "search"},
+      "properties": {
+        "name": {"type": "keyword", "ignore_above": 256},
+        "address": {"properties": {"city": {"type": "text", "analyzer": "folding"}}},
+        "indexed_later": {"type": "long"}
+      }
+    },
+    "settings": {
+      "index": {
+        "number_of_shards": "1",
+        "number_of_replicas": "1",
+        "provided_name": "my-index",
+        "analysis": {
+          "analyzer": {
+            "folding": {"tokenizer": "standard", "filter": ["lowercase", "asciifolding"]},
+            "my": {"analyzer": {"type": "custom", "tokenizer": "whitespace"}}
+          },
+          "tokenizer": {"ngram": {"type": "ngram", "min_gram": "3", "max_gram": "4"}}
+        }
+      }
+    }
+  }
+}`))
+	})
+
+	r := resourceElasticsearchIndex()
+	read := func(id string, state map[string]interface{}) *schema.ResourceData {
+		t.Helper()
+		d := r.TestResourceData()
+		d.SetId(id)
+		ds := &resourceDataSetter{d: d}
+		ds.set("force_destroy", false)
+		ds.set("allow_close_for_updates", false)
+		for k, v := range state {
+			ds.set(k, v)
+		}
+		if ds.err != nil {
+			t.Fatalf("err: %v", ds.err)
+		}
+		if diags := resourceElasticsearchIndexRead(context.TODO(), d, conf); diags.HasError() {
+			t.Fatalf("err: %v", diags)
+		}
+		return d
+	}
+	plan := func(d *schema.ResourceData, config map[string]interface{}) *terraform.InstanceDiff {
+		t.Helper()
+		raw := map[string]interface{}{"name": "my-index", "number_of_shards": "1", "number_of_replicas": "1"}
+		for k, v := range config {
+			raw[k] = v
+		}
+		diff, err := r.Diff(context.TODO(), d.State(), terraform.NewResourceConfigRaw(raw), conf)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return diff
+	}
+
+	// the values read back on import are equivalent to the configuration
+	d := read("my-index", nil)
+	if got := d.Get("name"); got != "my-index" {
+		t.Errorf("expected name my-index, got %v", got)
+	}
+	config := map[string]interface{}{
+		"aliases":            `{"search": {"routing": 1}}`,
+		"mappings":           `{"properties": {"name": {"type": "keyword", "ignore_above": 256}, "address": {"type": "object", "properties": {"city": {"type": "text", "analyzer": "folding"}}}}}`,
+		"analysis_analyzer":  `{"folding": {"tokenizer": "standard", "filter": ["lowercase", "asciifolding"]}, "my.analyzer": {"type": "custom", "tokenizer": "whitespace"}}`,
+		"analysis_tokenizer": `{"ngram": {"type": "ngram", "min_gram": 3, "max_gram": 4}}`,
+	}
+	if diff := plan(d, config); !diff.Empty() {
+		t.Errorf("expected no diff, got %v", diff)
+	}
+	// analyzer names may contain dots
+	expectedAnalyzers := `{"folding":{"filter":["lowercase","asciifolding"],"tokenizer":"standard"},"my.analyzer":{"tokenizer":"whitespace","type":"custom"}}`
+	if got := d.Get("analysis_analyzer"); got != expectedAnalyzers {
+		t.Errorf("expected analyzers %s, got %s", expectedAnalyzers, got)
+	}
+
+	// changes made outside of Terraform show as a diff
+	changed := map[string]interface{}{}
+	for k, v := range config {
+		changed[k] = v
+	}
+	changed["mappings"] = `{"properties": {"name": {"type": "keyword", "ignore_above": 512}, "address": {"properties": {"city": {"type": "text", "analyzer": "folding"}}}}}`
+	diff := plan(d, changed)
+	if diff.Empty() || diff.Attributes["mappings"] == nil || diff.RequiresNew() {
+		t.Errorf("expected the raised ignore_above to be updated in place, got %v", diff)
+	}
+	changed["analysis_tokenizer"] = `{"ngram": {"type": "ngram", "min_gram": 2, "max_gram": 4}}`
+	if diff := plan(d, changed); diff.Empty() || !diff.Attributes["analysis_tokenizer"].RequiresNew {
+		t.Errorf("expected the changed tokenizer to replace the index, got %v", diff)
+	}
+
+	// once imported, only the managed attributes are read back, not the ones
+	// added by index templates
+	d = read("my-index", map[string]interface{}{"name": "my-index", "mappings": config["mappings"]})
+	for _, attr := range []string{"aliases", "analysis_analyzer", "analysis_tokenizer"} {
+		if got := d.Get(attr); got != "" {
+			t.Errorf("expected unmanaged %s to be empty, got %v", attr, got)
+		}
+	}
+	if diff := plan(d, map[string]interface{}{"mappings": config["mappings"]}); !diff.Empty() {
+		t.Errorf("expected no diff, got %v", diff)
+	}
+
+	d = read("missing", nil)
+	if d.Id() != "" {
+		t.Errorf("expected a missing index to be removed from state")
+	}
+}
+
+// newTestIndexClient returns the conf and client of a provider for a test
+// server answering as Elasticsearch 7.17, and passing other requests than the
+// version check to handler.
+func newTestIndexClient(t *testing.T, handler http.HandlerFunc) (*ProviderConf, interface{}) {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			_, _ = w.Write([]byte(`{"version": {"number": "7.17.0", "build_flavor": "default"}}`))
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	parsedUrl, _ := url.Parse(ts.URL)
+	conf := &ProviderConf{
+		rawUrl:             ts.URL,
+		urls:               []string{ts.URL},
+		parsedUrl:          parsedUrl,
+		pingTimeoutSeconds: 5,
+	}
+	esClient, err := getClient(conf)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return conf, esClient
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	elastic7 "github.com/olivere/elastic/v7"
@@ -13,10 +15,10 @@ import (
 
 func resourceElasticsearchIngestPipeline() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchIngestPipelineCreate,
-		Read:   resourceElasticsearchIngestPipelineRead,
-		Update: resourceElasticsearchIngestPipelineUpdate,
-		Delete: resourceElasticsearchIngestPipelineDelete,
+		CreateContext: resourceElasticsearchIngestPipelineCreate,
+		ReadContext:   resourceElasticsearchIngestPipelineRead,
+		UpdateContext: resourceElasticsearchIngestPipelineUpdate,
+		DeleteContext: resourceElasticsearchIngestPipelineDelete,
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -33,49 +35,55 @@ func resourceElasticsearchIngestPipeline() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchIngestPipelineCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchIngestPipelineCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 
-	err := resourceElasticsearchPutIngestPipeline(d, meta)
+	err := resourceElasticsearchPutIngestPipeline(ctx, d, meta)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId(d.Get("name").(string))
 	return nil
 }
 
-func resourceElasticsearchIngestPipelineRead(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchIngestPipelineRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
 	var result string
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		result, err = elastic7IngestGetPipeline(client, id)
+		result, err = elastic7IngestGetPipeline(ctx, client, id)
 	case *elastic6.Client:
-		result, err = elastic6IngestGetPipeline(client, id)
+		result, err = elastic6IngestGetPipeline(ctx, client, id)
 	default:
-		return errors.New("Elasticsearch version not supported")
+		return diag.FromErr(errors.New("Elasticsearch version not supported"))
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	ds := &resourceDataSetter{d: d}
 	ds.set("name", d.Id())
 	ds.set("body", result)
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func elastic7IngestGetPipeline(client *elastic7.Client, id string) (string, error) {
+func elastic7IngestGetPipeline(ctx context.Context, client *elastic7.Client, id string) (string, error) {
 
-	res, err := client.IngestGetPipeline().Pretty(false).Do(context.TODO())
+	res, err := client.IngestGetPipeline().Pretty(false).Do(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -90,8 +98,8 @@ func elastic7IngestGetPipeline(client *elastic7.Client, id string) (string, erro
 	return string(tj), nil
 }
 
-func elastic6IngestGetPipeline(client *elastic6.Client, id string) (string, error) {
-	res, err := client.IngestGetPipeline(id).Do(context.TODO())
+func elastic6IngestGetPipeline(ctx context.Context, client *elastic6.Client, id string) (string, error) {
+	res, err := client.IngestGetPipeline(id).Do(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -104,35 +112,35 @@ func elastic6IngestGetPipeline(client *elastic6.Client, id string) (string, erro
 	return string(tj), nil
 }
 
-func resourceElasticsearchIngestPipelineUpdate(d *schema.ResourceData, meta interface{}) error {
-	return resourceElasticsearchPutIngestPipeline(d, meta)
+func resourceElasticsearchIngestPipelineUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return diag.FromErr(resourceElasticsearchPutIngestPipeline(ctx, d, meta))
 }
 
-func resourceElasticsearchIngestPipelineDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchIngestPipelineDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.IngestDeletePipeline(id).Do(context.TODO())
+		_, err = client.IngestDeletePipeline(id).Do(ctx)
 	case *elastic6.Client:
-		_, err = client.IngestDeletePipeline(id).Do(context.TODO())
+		_, err = client.IngestDeletePipeline(id).Do(ctx)
 	default:
-		return errors.New("Elasticsearch version not supported")
+		return diag.FromErr(errors.New("Elasticsearch version not supported"))
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId("")
 	return nil
 }
 
-func resourceElasticsearchPutIngestPipeline(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchPutIngestPipeline(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	name := d.Get("name").(string)
 	body := d.Get("body").(string)
 
@@ -143,9 +151,9 @@ func resourceElasticsearchPutIngestPipeline(d *schema.ResourceData, meta interfa
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.IngestPutPipeline(name).BodyString(body).Do(context.TODO())
+		_, err = client.IngestPutPipeline(name).BodyString(body).Do(ctx)
 	case *elastic6.Client:
-		_, err = client.IngestPutPipeline(name).BodyString(body).Do(context.TODO())
+		_, err = client.IngestPutPipeline(name).BodyString(body).Do(ctx)
 	default:
 		return errors.New("Elasticsearch version not supported")
 	}
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -17,15 +19,13 @@ import (
 	"github.com/phillbaker/terraform-provider-elasticsearch/kibana"
 )
 
-var minimalKibanaVersion, _ = version.NewVersion("7.7.0")
-var notifyWhenKibanaVersion, _ = version.NewVersion("7.11.0")
-
 func resourceElasticsearchKibanaAlert() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchKibanaAlertCreate,
-		Read:   resourceElasticsearchKibanaAlertRead,
-		Update: resourceElasticsearchKibanaAlertUpdate,
-		Delete: resourceElasticsearchKibanaAlertDelete,
+		CreateContext: resourceElasticsearchKibanaAlertCreate,
+		ReadContext:   resourceElasticsearchKibanaAlertRead,
+		UpdateContext: resourceElasticsearchKibanaAlertUpdate,
+		DeleteContext: resourceElasticsearchKibanaAlertDelete,
+		CustomizeDiff: capabilityCustomizeDiff("elasticsearch_kibana_alert"),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -203,18 +203,24 @@ func resourceElasticsearchKibanaAlert() *schema.Resource {
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 		Description: "Alerts allow you to define rules to detect conditions and trigger actions when those conditions are met. Alerts work by running checks on a schedule to detect conditions. When a condition is met, the alert tracks it as an alert instance and responds by triggering one or more actions. Actions typically involve interaction with Kibana services or third party integrations. For more see the [docs](https://www.elastic.co/guide/en/kibana/current/alerting-getting-started.html).",
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchKibanaAlertCreate(d *schema.ResourceData, meta interface{}) error {
-	err := resourceElasticsearchKibanaAlertCheckVersion(meta)
+func resourceElasticsearchKibanaAlertCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	err := checkCapability(meta, "elasticsearch_kibana_alert")
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	id, err := resourceElasticsearchPostKibanaAlert(d, meta)
+	id, err := resourceElasticsearchPostKibanaAlert(ctx, d, meta)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	log.Printf("[INFO] Kibana Alert (%s) created", id)
@@ -223,10 +229,10 @@ func resourceElasticsearchKibanaAlertCreate(d *schema.ResourceData, meta interfa
 	return nil
 }
 
-func resourceElasticsearchKibanaAlertRead(d *schema.ResourceData, meta interface{}) error {
-	err := resourceElasticsearchKibanaAlertCheckVersion(meta)
+func resourceElasticsearchKibanaAlertRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	err := checkCapability(meta, "elasticsearch_kibana_alert")
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	id := d.Id()
@@ -237,12 +243,12 @@ func resourceElasticsearchKibanaAlertRead(d *schema.ResourceData, meta interface
 	providerConf := meta.(*ProviderConf)
 	kibanaClient, err := getKibanaClient(providerConf)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	switch client := kibanaClient.(type) {
 	case *elastic7.Client:
-		alert, err = kibanaGetAlert(client, id, spaceID)
+		alert, err = kibanaGetAlert(ctx, client, id, spaceID)
 	default:
 		err = fmt.Errorf("Kibana Alert endpoint only available from Kibana >= 7.7, got version < 7.0.0")
 	}
@@ -254,7 +260,7 @@ func resourceElasticsearchKibanaAlertRead(d *schema.ResourceData, meta interface
 			return nil
 		}
 
-		return err
+		return diag.FromErr(err)
 	}
 
 	schedule := make([]map[string]interface{}, 0, 1)
@@ -272,7 +278,7 @@ func resourceElasticsearchKibanaAlertRead(d *schema.ResourceData, meta interface
 	if _, ok := d.GetOk("params_json"); ok {
 		pj, err := json.Marshal(alert.Params)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		ds.set("params_json", string(pj))
 	} else {
@@ -281,26 +287,26 @@ func resourceElasticsearchKibanaAlertRead(d *schema.ResourceData, meta interface
 
 	actions, err := flattenKibanaAlertActions(alert.Actions, d.Get("actions").([]interface{}))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	ds.set("actions", actions)
 
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func resourceElasticsearchKibanaAlertUpdate(d *schema.ResourceData, meta interface{}) error {
-	err := resourceElasticsearchKibanaAlertCheckVersion(meta)
+func resourceElasticsearchKibanaAlertUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	err := checkCapability(meta, "elasticsearch_kibana_alert")
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceElasticsearchPutKibanaAlert(d, meta)
+	return diag.FromErr(resourceElasticsearchPutKibanaAlert(d, meta))
 }
 
-func resourceElasticsearchKibanaAlertDelete(d *schema.ResourceData, meta interface{}) error {
-	err := resourceElasticsearchKibanaAlertCheckVersion(meta)
+func resourceElasticsearchKibanaAlertDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	err := checkCapability(meta, "elasticsearch_kibana_alert")
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	id := d.Id()
@@ -309,24 +315,24 @@ func resourceElasticsearchKibanaAlertDelete(d *schema.ResourceData, meta interfa
 	providerConf := meta.(*ProviderConf)
 	kibanaClient, err := getKibanaClient(providerConf)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	switch client := kibanaClient.(type) {
 	case *elastic7.Client:
-		err = kibanaDeleteAlert(client, id, spaceID)
+		err = kibanaDeleteAlert(ctx, client, id, spaceID)
 	default:
 		err = fmt.Errorf("Kibana Alert endpoint only available from ElasticSearch >= 7.7, got version < 7.0.0")
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId("")
 	return nil
 }
 
-func resourceElasticsearchPostKibanaAlert(d *schema.ResourceData, meta interface{}) (string, error) {
+func resourceElasticsearchPostKibanaAlert(ctx context.Context, d *schema.ResourceData, meta interface{}) (string, error) {
 	spaceID := ""
 
 	providerConf := meta.(*ProviderConf)
@@ -372,15 +378,14 @@ func resourceElasticsearchPostKibanaAlert(d *schema.ResourceData, meta interface
 		Actions:     actions,
 	}
 
-	version, _ := resourceElasticsearchKibanaGetVersion(meta)
-	if version.GreaterThanOrEqual(notifyWhenKibanaVersion) {
+	if hasCapability(meta, "elasticsearch_kibana_alert.notify_when") {
 		alert.NotifyWhen = d.Get("notify_when").(string)
 	}
 
 	var id string
 	switch client := kibanaClient.(type) {
 	case *elastic7.Client:
-		id, err = kibanaPostAlert(client, spaceID, alert)
+		id, err = kibanaPostAlert(ctx, client, spaceID, alert)
 	default:
 		err = fmt.Errorf("Kibana Alert endpoint only available from ElasticSearch >= 7.7, got version < 7.0.0")
 	}
@@ -495,34 +500,10 @@ func resourceElasticsearchPutKibanaAlert(d *schema.ResourceData, meta interface{
 }
 
 func resourceElasticsearchKibanaGetVersion(meta interface{}) (*version.Version, error) {
-	providerConf := meta.(*ProviderConf)
-	esClient, err := getClient(providerConf)
-	if err != nil {
-		return nil, err
-	}
-
-	switch esClient.(type) {
-	case *elastic7.Client:
-		return version.NewVersion(providerConf.esVersion)
-	default:
-		return nil, fmt.Errorf("Kibana Alert endpoint only available from ElasticSearch >= 7.7, got version < 7.0.0")
-	}
-}
-
-func resourceElasticsearchKibanaAlertCheckVersion(meta interface{}) error {
-	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
-	if err != nil {
-		return err
-	}
-
-	if elasticVersion.LessThan(minimalKibanaVersion) {
-		return fmt.Errorf("Kibana Alert endpoint only available from ElasticSearch >= 7.7, got version %s", elasticVersion.String())
-	}
-
-	return err
+	return serverVersion(meta.(*ProviderConf))
 }
 
-func kibanaGetAlert(client *elastic7.Client, id, spaceID string) (kibana.Alert, error) {
+func kibanaGetAlert(ctx context.Context, client *elastic7.Client, id, spaceID string) (kibana.Alert, error) {
 	path, err := uritemplates.Expand("/api/alerts/alert/{id}", map[string]string{
 		"id": id,
 	})
@@ -532,7 +513,7 @@ func kibanaGetAlert(client *elastic7.Client, id, spaceID string) (kibana.Alert,
 
 	var body json.RawMessage
 	var res *elastic7.Response
-	res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+	res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 		Method: "GET",
 		Path:   path,
 	})
@@ -550,7 +531,7 @@ func kibanaGetAlert(client *elastic7.Client, id, spaceID string) (kibana.Alert,
 	return *alert, nil
 }
 
-func kibanaPostAlert(client *elastic7.Client, spaceID string, alert kibana.Alert) (string, error) {
+func kibanaPostAlert(ctx context.Context, client *elastic7.Client, spaceID string, alert kibana.Alert) (string, error) {
 	path, err := uritemplates.Expand("/api/alerts/alert", map[string]string{})
 	if err != nil {
 		return "", fmt.Errorf("error building URL path for alert: %+v", err)
@@ -563,7 +544,7 @@ func kibanaPostAlert(client *elastic7.Client, spaceID string, alert kibana.Alert
 	}
 
 	var res *elastic7.Response
-	res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+	res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 		Method: "POST",
 		Path:   path,
 		Body:   string(body[:]),
@@ -581,7 +562,7 @@ func kibanaPostAlert(client *elastic7.Client, spaceID string, alert kibana.Alert
 	return alert.ID, nil
 }
 
-func kibanaDeleteAlert(client *elastic7.Client, id, spaceID string) error {
+func kibanaDeleteAlert(ctx context.Context, client *elastic7.Client, id, spaceID string) error {
 	path, err := uritemplates.Expand("/api/alerts/alert/{id}", map[string]string{
 		"id": id,
 	})
@@ -589,7 +570,7 @@ func kibanaDeleteAlert(client *elastic7.Client, id, spaceID string) error {
 		return fmt.Errorf("error building URL path for alert: %+v", err)
 	}
 
-	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+	_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 		Method: "DELETE",
 		Path:   path,
 	})
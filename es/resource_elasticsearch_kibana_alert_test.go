@@ -184,7 +184,7 @@ func testCheckElasticsearchKibanaAlertExists(name string) resource.TestCheckFunc
 
 		switch client := esClient.(type) {
 		case *elastic7.Client:
-			_, err = kibanaGetAlert(client, rs.Primary.ID, "")
+			_, err = kibanaGetAlert(context.TODO(), client, rs.Primary.ID, "")
 		default:
 			err = errors.New("Kibana Alerts only supported on ES >= 7.7")
 		}
@@ -212,7 +212,7 @@ func testCheckElasticsearchKibanaAlertDestroy(s *terraform.State) error {
 
 		switch client := esClient.(type) {
 		case *elastic7.Client:
-			_, err = kibanaGetAlert(client, rs.Primary.ID, "")
+			_, err = kibanaGetAlert(context.TODO(), client, rs.Primary.ID, "")
 		default:
 			err = errors.New("Kibana Alerts only supported on ES >= 7.7")
 		}
@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
 
@@ -16,10 +18,10 @@ import (
 
 func resourceElasticsearchKibanaObject() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchKibanaObjectCreate,
-		Read:   resourceElasticsearchKibanaObjectRead,
-		Update: resourceElasticsearchKibanaObjectUpdate,
-		Delete: resourceElasticsearchKibanaObjectDelete,
+		CreateContext: resourceElasticsearchKibanaObjectCreate,
+		ReadContext:   resourceElasticsearchKibanaObjectRead,
+		UpdateContext: resourceElasticsearchKibanaObjectUpdate,
+		DeleteContext: resourceElasticsearchKibanaObjectDelete,
 		Schema: map[string]*schema.Schema{
 			"body": {
 				Type:     schema.TypeString,
@@ -71,6 +73,12 @@ func resourceElasticsearchKibanaObject() *schema.Resource {
 				Default:  ".kibana",
 			},
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
@@ -82,7 +90,7 @@ const (
 
 const deprecatedDocType = "doc"
 
-func resourceElasticsearchKibanaObjectCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchKibanaObjectCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	index := d.Get("index").(string)
 	mapping_index := d.Get("index").(string)
 
@@ -90,33 +98,33 @@ func resourceElasticsearchKibanaObjectCreate(d *schema.ResourceData, meta interf
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		success, err = elastic7CreateIndexIfNotExists(client, index, mapping_index)
+		success, err = elastic7CreateIndexIfNotExists(ctx, client, index, mapping_index)
 	case *elastic6.Client:
-		success, err = elastic6CreateIndexIfNotExists(client, index, mapping_index)
+		success, err = elastic6CreateIndexIfNotExists(ctx, client, index, mapping_index)
 	default:
-		return errors.New("Elasticsearch version not supported")
+		return diag.FromErr(errors.New("Elasticsearch version not supported"))
 	}
 
 	if err != nil {
 		log.Printf("[INFO] Failed to create new kibana index: %+v", err)
-		return err
+		return diag.FromErr(err)
 	}
 
 	if success == INDEX_CREATED {
 		log.Printf("[INFO] Created new kibana index")
 	} else if success == INDEX_CREATION_FAILED {
-		return fmt.Errorf("fail to create the Elasticsearch index")
+		return diag.Errorf("fail to create the Elasticsearch index")
 	}
 
-	id, err := resourceElasticsearchPutKibanaObject(d, meta)
+	id, err := resourceElasticsearchPutKibanaObject(ctx, d, meta)
 
 	if err != nil {
 		log.Printf("[INFO] Failed to put kibana object: %+v", err)
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(id)
@@ -125,16 +133,16 @@ func resourceElasticsearchKibanaObjectCreate(d *schema.ResourceData, meta interf
 	return nil
 }
 
-func elastic7CreateIndexIfNotExists(client *elastic7.Client, index string, mappingIndex string) (int, error) {
+func elastic7CreateIndexIfNotExists(ctx context.Context, client *elastic7.Client, index string, mappingIndex string) (int, error) {
 	log.Printf("[INFO] elastic7CreateIndexIfNotExists %s", index)
 
 	// Use the IndexExists service to check if a specified index exists.
-	exists, err := client.IndexExists(index).Do(context.TODO())
+	exists, err := client.IndexExists(index).Do(ctx)
 	if err != nil {
 		return INDEX_CREATION_FAILED, err
 	}
 	if !exists {
-		createIndex, err := client.CreateIndex(mappingIndex).Body(`{"mappings":{}}`).Do(context.TODO())
+		createIndex, err := client.CreateIndex(mappingIndex).Body(`{"mappings":{}}`).Do(ctx)
 		if createIndex.Acknowledged {
 			return INDEX_CREATED, err
 		}
@@ -144,16 +152,16 @@ func elastic7CreateIndexIfNotExists(client *elastic7.Client, index string, mappi
 	return INDEX_EXISTS, nil
 }
 
-func elastic6CreateIndexIfNotExists(client *elastic6.Client, index string, mapping_index string) (int, error) {
+func elastic6CreateIndexIfNotExists(ctx context.Context, client *elastic6.Client, index string, mapping_index string) (int, error) {
 	log.Printf("[INFO] elastic6CreateIndexIfNotExists")
 
 	// Use the IndexExists service to check if a specified index exists.
-	exists, err := client.IndexExists(index).Do(context.TODO())
+	exists, err := client.IndexExists(index).Do(ctx)
 	if err != nil {
 		return INDEX_CREATION_FAILED, err
 	}
 	if !exists {
-		createIndex, err := client.CreateIndex(mapping_index).Body(`{"mappings":{}}`).Do(context.TODO())
+		createIndex, err := client.CreateIndex(mapping_index).Body(`{"mappings":{}}`).Do(ctx)
 		if createIndex.Acknowledged {
 			return INDEX_CREATED, err
 		} else {
@@ -164,16 +172,16 @@ func elastic6CreateIndexIfNotExists(client *elastic6.Client, index string, mappi
 	return INDEX_EXISTS, nil
 }
 
-func resourceElasticsearchKibanaObjectRead(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchKibanaObjectRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	bodyString := d.Get("body").(string)
 	var body []interface{}
 	if err := json.Unmarshal([]byte(bodyString), &body); err != nil {
 		log.Printf("[WARN] Failed to unmarshal on read: %+v", bodyString)
-		return err
+		return diag.FromErr(err)
 	}
 	kibanaObject, ok := body[0].(map[string]interface{})
 	if !ok {
-		return fmt.Errorf("expected %v to be an object", body[0])
+		return diag.Errorf("expected %v to be an object", body[0])
 	}
 	id := kibanaObject["_id"].(string)
 	objectType := objectTypeOrDefault(kibanaObject)
@@ -183,23 +191,23 @@ func resourceElasticsearchKibanaObjectRead(d *schema.ResourceData, meta interfac
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var result *elastic7.GetResult
-		result, err = elastic7GetObject(client, index, id)
+		result, err = elastic7GetObject(ctx, client, index, id)
 		if err == nil {
 			resultJSON, err = json.Marshal(result)
 		}
 	case *elastic6.Client:
 		var result *elastic6.GetResult
-		result, err = elastic6GetObject(client, objectType, index, id)
+		result, err = elastic6GetObject(ctx, client, objectType, index, id)
 		if err == nil {
 			resultJSON, err = json.Marshal(result)
 		}
 	default:
-		return errors.New("Elasticsearch version not supported")
+		return diag.FromErr(errors.New("Elasticsearch version not supported"))
 	}
 
 	if err != nil {
@@ -209,7 +217,7 @@ func resourceElasticsearchKibanaObjectRead(d *schema.ResourceData, meta interfac
 			return nil
 		}
 
-		return err
+		return diag.FromErr(err)
 	}
 	log.Printf("[TRACE] body: %s", string(resultJSON))
 
@@ -229,7 +237,7 @@ func resourceElasticsearchKibanaObjectRead(d *schema.ResourceData, meta interfac
 	result := make(map[string]interface{})
 	if err := json.Unmarshal(resultJSON, &result); err != nil {
 		log.Printf("[WARN] Failed to unmarshal: %+v", resultJSON)
-		return err
+		return diag.FromErr(err)
 	}
 
 	stateObject := []map[string]interface{}{make(map[string]interface{})}
@@ -238,28 +246,28 @@ func resourceElasticsearchKibanaObjectRead(d *schema.ResourceData, meta interfac
 	}
 	state, err := json.Marshal(stateObject)
 	if err != nil {
-		return fmt.Errorf("error marshalling resource data: %+v", err)
+		return diag.Errorf("error marshalling resource data: %+v", err)
 	}
 	ds.set("body", string(state))
 
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func resourceElasticsearchKibanaObjectUpdate(d *schema.ResourceData, meta interface{}) error {
-	_, err := resourceElasticsearchPutKibanaObject(d, meta)
-	return err
+func resourceElasticsearchKibanaObjectUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	_, err := resourceElasticsearchPutKibanaObject(ctx, d, meta)
+	return diag.FromErr(err)
 }
 
-func resourceElasticsearchKibanaObjectDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchKibanaObjectDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	bodyString := d.Get("body").(string)
 	var body []interface{}
 	if err := json.Unmarshal([]byte(bodyString), &body); err != nil {
 		log.Printf("[WARN] Failed to unmarshal: %+v", bodyString)
-		return err
+		return diag.FromErr(err)
 	}
 	object, ok := body[0].(map[string]interface{})
 	if !ok {
-		return fmt.Errorf("expected %v to be an object", body[0])
+		return diag.Errorf("expected %v to be an object", body[0])
 	}
 	id := object["_id"].(string)
 	objectType := objectTypeOrDefault(object)
@@ -268,46 +276,46 @@ func resourceElasticsearchKibanaObjectDelete(d *schema.ResourceData, meta interf
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		err = elastic7DeleteIndex(client, index, id)
+		err = elastic7DeleteIndex(ctx, client, index, id)
 	case *elastic6.Client:
-		err = elastic6DeleteIndex(client, objectType, index, id)
+		err = elastic6DeleteIndex(ctx, client, objectType, index, id)
 	default:
-		return errors.New("Elasticsearch version not supported")
+		return diag.FromErr(errors.New("Elasticsearch version not supported"))
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	return nil
 }
 
-func elastic7DeleteIndex(client *elastic7.Client, index string, id string) error {
+func elastic7DeleteIndex(ctx context.Context, client *elastic7.Client, index string, id string) error {
 	_, err := client.Delete().
 		Index(index).
 		Id(id).
-		Do(context.TODO())
+		Do(ctx)
 
 	// we'll get an error if it's not found
 	return err
 }
 
-func elastic6DeleteIndex(client *elastic6.Client, objectType string, index string, id string) error {
+func elastic6DeleteIndex(ctx context.Context, client *elastic6.Client, objectType string, index string, id string) error {
 	_, err := client.Delete().
 		Index(index).
 		Type(objectType).
 		Id(id).
-		Do(context.TODO())
+		Do(ctx)
 
 	// we'll get an error if it's not found: https://github.com/olivere/elastic/blob/v6.1.26/delete.go#L207-L210
 	return err
 }
 
-func resourceElasticsearchPutKibanaObject(d *schema.ResourceData, meta interface{}) (string, error) {
+func resourceElasticsearchPutKibanaObject(ctx context.Context, d *schema.ResourceData, meta interface{}) (string, error) {
 	bodyString := d.Get("body").(string)
 	var body []interface{}
 	if err := json.Unmarshal([]byte(bodyString), &body); err != nil {
@@ -330,9 +338,9 @@ func resourceElasticsearchPutKibanaObject(d *schema.ResourceData, meta interface
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		err = elastic7PutIndex(client, index, id, data)
+		err = elastic7PutIndex(ctx, client, index, id, data)
 	case *elastic6.Client:
-		err = elastic6PutIndex(client, objectType, index, id, data)
+		err = elastic6PutIndex(ctx, client, objectType, index, id, data)
 	default:
 		err = errors.New("Elasticsearch version not supported")
 	}
@@ -344,23 +352,23 @@ func resourceElasticsearchPutKibanaObject(d *schema.ResourceData, meta interface
 	return id, nil
 }
 
-func elastic7PutIndex(client *elastic7.Client, index string, id string, data interface{}) error {
+func elastic7PutIndex(ctx context.Context, client *elastic7.Client, index string, id string, data interface{}) error {
 	_, err := client.Index().
 		Index(index).
 		Id(id).
 		BodyJson(&data).
-		Do(context.TODO())
+		Do(ctx)
 
 	return err
 }
 
-func elastic6PutIndex(client *elastic6.Client, objectType string, index string, id string, data interface{}) error {
+func elastic6PutIndex(ctx context.Context, client *elastic6.Client, objectType string, index string, id string, data interface{}) error {
 	_, err := client.Index().
 		Index(index).
 		Type(objectType).
 		Id(id).
 		BodyJson(&data).
-		Do(context.TODO())
+		Do(ctx)
 
 	return err
 }
@@ -6,11 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/olivere/elastic/uritemplates"
 
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic6 "gopkg.in/olivere/elastic.v6"
@@ -35,52 +36,64 @@ var openDistroDestinationSchema = map[string]*schema.Schema{
 
 func resourceOpenSearchDestination() *schema.Resource {
 	return &schema.Resource{
-		Description: "Provides an OpenSearch destination, a reusable communication channel for an action, such as email, Slack, or a webhook URL. Please refer to the OpenDistro [destination documentation](https://opendistro.github.io/for-elasticsearch-docs/docs/alerting/monitors/#create-destinations) for details.",
-		Create:      resourceElasticsearchOpenDistroDestinationCreate,
-		Read:        resourceElasticsearchOpenDistroDestinationRead,
-		Update:      resourceElasticsearchOpenDistroDestinationUpdate,
-		Delete:      resourceElasticsearchOpenDistroDestinationDelete,
-		Schema:      openDistroDestinationSchema,
+		Description:   "Provides an OpenSearch destination, a reusable communication channel for an action, such as email, Slack, or a webhook URL. Please refer to the OpenDistro [destination documentation](https://opendistro.github.io/for-elasticsearch-docs/docs/alerting/monitors/#create-destinations) for details.",
+		CreateContext: resourceElasticsearchOpenDistroDestinationCreate,
+		ReadContext:   resourceElasticsearchOpenDistroDestinationRead,
+		UpdateContext: resourceElasticsearchOpenDistroDestinationUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroDestinationDelete,
+		Schema:        openDistroDestinationSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
 func resourceElasticsearchOpenDistroDestination() *schema.Resource {
 	return &schema.Resource{
-		Description: "Provides an Elasticsearch OpenDistro destination, a reusable communication channel for an action, such as email, Slack, or a webhook URL. Please refer to the OpenDistro [destination documentation](https://opendistro.github.io/for-elasticsearch-docs/docs/alerting/monitors/#create-destinations) for details.",
-		Create:      resourceElasticsearchOpenDistroDestinationCreate,
-		Read:        resourceElasticsearchOpenDistroDestinationRead,
-		Update:      resourceElasticsearchOpenDistroDestinationUpdate,
-		Delete:      resourceElasticsearchOpenDistroDestinationDelete,
-		Schema:      openDistroDestinationSchema,
+		Description:   "Provides an Elasticsearch OpenDistro destination, a reusable communication channel for an action, such as email, Slack, or a webhook URL. Please refer to the OpenDistro [destination documentation](https://opendistro.github.io/for-elasticsearch-docs/docs/alerting/monitors/#create-destinations) for details.",
+		CreateContext: resourceElasticsearchOpenDistroDestinationCreate,
+		ReadContext:   resourceElasticsearchOpenDistroDestinationRead,
+		UpdateContext: resourceElasticsearchOpenDistroDestinationUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroDestinationDelete,
+		Schema:        openDistroDestinationSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 		DeprecationMessage: "elasticsearch_opendistro_destination is deprecated, please use elasticsearch_opensearch_destination resource instead.",
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchOpenDistroDestinationCreate(d *schema.ResourceData, m interface{}) error {
-	res, err := resourceElasticsearchOpenDistroPostDestination(d, m)
+func resourceElasticsearchOpenDistroDestinationCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	res, err := resourceElasticsearchOpenDistroPostDestination(ctx, d, m)
 
 	if err != nil {
 		log.Printf("[INFO] Failed to put destination: %+v", err)
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(res.ID)
 	destination, err := json.Marshal(res.Destination)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	err = d.Set("body", string(destination))
-	return err
+	return diag.FromErr(err)
 }
 
-func resourceElasticsearchOpenDistroDestinationRead(d *schema.ResourceData, m interface{}) error {
-	destination, err := resourceElasticsearchOpenDistroQueryOrGetDestination(d.Id(), m)
+func resourceElasticsearchOpenDistroDestinationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	destination, err := resourceElasticsearchOpenDistroQueryOrGetDestination(ctx, d.Id(), m)
 
 	if elastic6.IsNotFound(err) || elastic7.IsNotFound(err) {
 		log.Printf("[WARN] Destination (%s) not found, removing from state", d.Id())
@@ -89,50 +102,50 @@ func resourceElasticsearchOpenDistroDestinationRead(d *schema.ResourceData, m in
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	body, err := json.Marshal(destination)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	err = d.Set("body", string(body))
-	return err
+	return diag.FromErr(err)
 }
 
-func resourceElasticsearchOpenDistroDestinationUpdate(d *schema.ResourceData, m interface{}) error {
-	_, err := resourceElasticsearchOpenDistroPutDestination(d, m)
+func resourceElasticsearchOpenDistroDestinationUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	_, err := resourceElasticsearchOpenDistroPutDestination(ctx, d, m)
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceElasticsearchOpenDistroDestinationRead(d, m)
+	return resourceElasticsearchOpenDistroDestinationRead(ctx, d, m)
 }
 
-func resourceElasticsearchOpenDistroDestinationDelete(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchOpenDistroDestinationDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var err error
 
-	path, err := uritemplates.Expand("/_opendistro/_alerting/destinations/{id}", map[string]string{
+	path, err := pluginPath(m, "_alerting/destinations/{id}", map[string]string{
 		"id": d.Id(),
 	})
 	if err != nil {
-		return fmt.Errorf("error building URL path for destination: %+v", err)
+		return diag.Errorf("error building URL path for destination: %+v", err)
 	}
 
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "DELETE",
 			Path:   path,
 		})
 	case *elastic6.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "DELETE",
 			Path:   path,
 		})
@@ -140,20 +153,20 @@ func resourceElasticsearchOpenDistroDestinationDelete(d *schema.ResourceData, m
 		err = errors.New("destination resource not implemented prior to Elastic v6")
 	}
 
-	return err
+	return diag.FromErr(err)
 }
 
-func resourceElasticsearchOpenDistroGetDestination(destinationID string, esClient interface{}) (Destination, error) {
+func resourceElasticsearchOpenDistroGetDestination(ctx context.Context, destinationID string, esClient interface{}, m interface{}) (Destination, error) {
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		path, err := uritemplates.Expand("/_opendistro/_alerting/destinations/{id}", map[string]string{
+		path, err := pluginPath(m, "_alerting/destinations/{id}", map[string]string{
 			"id": destinationID,
 		})
 		if err != nil {
 			return Destination{}, fmt.Errorf("error building URL path for destination: %+v", err)
 		}
 
-		httpResponse, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		httpResponse, err := client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "GET",
 			Path:   path,
 		})
@@ -178,7 +191,7 @@ func resourceElasticsearchOpenDistroGetDestination(destinationID string, esClien
 	}
 }
 
-func resourceElasticsearchOpenDistroQueryOrGetDestination(destinationID string, m interface{}) (Destination, error) {
+func resourceElasticsearchOpenDistroQueryOrGetDestination(ctx context.Context, destinationID string, m interface{}) (Destination, error) {
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return Destination{}, err
@@ -190,11 +203,11 @@ func resourceElasticsearchOpenDistroQueryOrGetDestination(destinationID string,
 		// See https://github.com/opendistro-for-elasticsearch/alerting/issues/56,
 		// no API endpoint for retrieving destination prior to ODFE 1.11.0. So do
 		// a request, if it 404s, fall back to trying to query the index.
-		destination, err := resourceElasticsearchOpenDistroGetDestination(destinationID, client)
+		destination, err := resourceElasticsearchOpenDistroGetDestination(ctx, destinationID, client, m)
 		if err == nil {
 			return destination, err
 		} else {
-			result, err := elastic7GetObject(client, DESTINATION_INDEX, destinationID)
+			result, err := elastic7GetObject(ctx, client, DESTINATION_INDEX, destinationID)
 
 			if err != nil {
 				return Destination{}, err
@@ -205,7 +218,7 @@ func resourceElasticsearchOpenDistroQueryOrGetDestination(destinationID string,
 			return dr.Destination, nil
 		}
 	case *elastic6.Client:
-		result, err := elastic6GetObject(client, DESTINATION_TYPE, DESTINATION_INDEX, destinationID)
+		result, err := elastic6GetObject(ctx, client, DESTINATION_TYPE, DESTINATION_INDEX, destinationID)
 		if err != nil {
 			return Destination{}, err
 		}
@@ -218,13 +231,16 @@ func resourceElasticsearchOpenDistroQueryOrGetDestination(destinationID string,
 	}
 }
 
-func resourceElasticsearchOpenDistroPostDestination(d *schema.ResourceData, m interface{}) (*destinationResponse, error) {
+func resourceElasticsearchOpenDistroPostDestination(ctx context.Context, d *schema.ResourceData, m interface{}) (*destinationResponse, error) {
 	destinationJSON := d.Get("body").(string)
 
 	var err error
 	response := new(destinationResponse)
 
-	path := "/_opendistro/_alerting/destinations/"
+	path, err := pluginPath(m, "_alerting/destinations/", nil)
+	if err != nil {
+		return response, fmt.Errorf("error building URL path for destination: %+v", err)
+	}
 
 	var body json.RawMessage
 	esClient, err := getClient(m.(*ProviderConf))
@@ -234,7 +250,7 @@ func resourceElasticsearchOpenDistroPostDestination(d *schema.ResourceData, m in
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "POST",
 			Path:   path,
 			Body:   destinationJSON,
@@ -245,7 +261,7 @@ func resourceElasticsearchOpenDistroPostDestination(d *schema.ResourceData, m in
 		body = res.Body
 	case *elastic6.Client:
 		var res *elastic6.Response
-		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "POST",
 			Path:   path,
 			Body:   destinationJSON,
@@ -265,13 +281,13 @@ func resourceElasticsearchOpenDistroPostDestination(d *schema.ResourceData, m in
 	return response, nil
 }
 
-func resourceElasticsearchOpenDistroPutDestination(d *schema.ResourceData, m interface{}) (*destinationResponse, error) {
+func resourceElasticsearchOpenDistroPutDestination(ctx context.Context, d *schema.ResourceData, m interface{}) (*destinationResponse, error) {
 	destinationJSON := d.Get("body").(string)
 
 	var err error
 	response := new(destinationResponse)
 
-	path, err := uritemplates.Expand("/_opendistro/_alerting/destinations/{id}", map[string]string{
+	path, err := pluginPath(m, "_alerting/destinations/{id}", map[string]string{
 		"id": d.Id(),
 	})
 	if err != nil {
@@ -286,7 +302,7 @@ func resourceElasticsearchOpenDistroPutDestination(d *schema.ResourceData, m int
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "PUT",
 			Path:   path,
 			Body:   destinationJSON,
@@ -297,7 +313,7 @@ func resourceElasticsearchOpenDistroPutDestination(d *schema.ResourceData, m int
 		body = res.Body
 	case *elastic6.Client:
 		var res *elastic6.Response
-		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "PUT",
 			Path:   path,
 			Body:   destinationJSON,
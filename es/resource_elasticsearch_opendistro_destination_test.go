@@ -1,6 +1,7 @@
 package es
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -62,7 +63,7 @@ func testCheckElasticsearchOpenDistroDestinationExists(name string) resource.Tes
 		meta := testAccOpendistroProvider.Meta()
 
 		var err error
-		_, err = resourceElasticsearchOpenDistroQueryOrGetDestination(rs.Primary.ID, meta.(*ProviderConf))
+		_, err = resourceElasticsearchOpenDistroQueryOrGetDestination(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 
 		if err != nil {
 			return err
@@ -87,9 +88,9 @@ func testCheckElasticsearchOpenDistroDestinationDestroy(s *terraform.State) erro
 		}
 		switch esClient.(type) {
 		case *elastic7.Client:
-			_, err = resourceElasticsearchOpenDistroQueryOrGetDestination(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchOpenDistroQueryOrGetDestination(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 		case *elastic6.Client:
-			_, err = resourceElasticsearchOpenDistroQueryOrGetDestination(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchOpenDistroQueryOrGetDestination(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 		default:
 		}
 
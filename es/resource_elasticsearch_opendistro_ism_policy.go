@@ -11,9 +11,9 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
-	"github.com/olivere/elastic/uritemplates"
 
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic6 "gopkg.in/olivere/elastic.v6"
@@ -48,44 +48,58 @@ var openDistroISMPolicySchema = map[string]*schema.Schema{
 
 func resourceOpenSearchISMPolicy() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroISMPolicyCreate,
-		Read:   resourceElasticsearchOpenDistroISMPolicyRead,
-		Update: resourceElasticsearchOpenDistroISMPolicyUpdate,
-		Delete: resourceElasticsearchOpenDistroISMPolicyDelete,
-		Schema: openDistroISMPolicySchema,
+		CreateContext: resourceElasticsearchOpenDistroISMPolicyCreate,
+		ReadContext:   resourceElasticsearchOpenDistroISMPolicyRead,
+		UpdateContext: resourceElasticsearchOpenDistroISMPolicyUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroISMPolicyDelete,
+		CustomizeDiff: serverlessCustomizeDiff("elasticsearch_opensearch_ism_policy"),
+		Schema:        openDistroISMPolicySchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
 func resourceElasticsearchOpenDistroISMPolicy() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroISMPolicyCreate,
-		Read:   resourceElasticsearchOpenDistroISMPolicyRead,
-		Update: resourceElasticsearchOpenDistroISMPolicyUpdate,
-		Delete: resourceElasticsearchOpenDistroISMPolicyDelete,
-		Schema: openDistroISMPolicySchema,
+		CreateContext: resourceElasticsearchOpenDistroISMPolicyCreate,
+		ReadContext:   resourceElasticsearchOpenDistroISMPolicyRead,
+		UpdateContext: resourceElasticsearchOpenDistroISMPolicyUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroISMPolicyDelete,
+		CustomizeDiff: serverlessCustomizeDiff("elasticsearch_opendistro_ism_policy"),
+		Schema:        openDistroISMPolicySchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 		DeprecationMessage: "elasticsearch_opendistro_ism_policy is deprecated, please use elasticsearch_opensearch_ism_policy resource instead.",
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchOpenDistroISMPolicyCreate(d *schema.ResourceData, m interface{}) error {
-	if _, err := resourceElasticsearchPutOpenDistroISMPolicy(d, m); err != nil {
+func resourceElasticsearchOpenDistroISMPolicyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if _, err := resourceElasticsearchPutOpenDistroISMPolicy(ctx, d, m); err != nil {
 		log.Printf("[INFO] Failed to create OpenDistroPolicy: %+v", err)
-		return err
+		return diag.FromErr(err)
 	}
 
 	policyID := d.Get("policy_id").(string)
 	d.SetId(policyID)
-	return resourceElasticsearchOpenDistroISMPolicyRead(d, m)
+	return resourceElasticsearchOpenDistroISMPolicyRead(ctx, d, m)
 }
 
-func resourceElasticsearchOpenDistroISMPolicyRead(d *schema.ResourceData, m interface{}) error {
-	policyResponse, err := resourceElasticsearchGetOpenDistroISMPolicy(d.Id(), m)
+func resourceElasticsearchOpenDistroISMPolicyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	policyResponse, err := resourceElasticsearchGetOpenDistroISMPolicy(ctx, d.Id(), m)
 
 	if err != nil {
 		if elastic6.IsNotFound(err) || elastic7.IsNotFound(err) {
@@ -93,56 +107,56 @@ func resourceElasticsearchOpenDistroISMPolicyRead(d *schema.ResourceData, m inte
 			d.SetId("")
 			return nil
 		}
-		return err
+		return diag.FromErr(err)
 	}
 
 	bodyString, err := json.Marshal(policyResponse.Policy)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	// Need encapsulation as the response from the GET is different than the one in the PUT
 	bodyStringNormalized, _ := structure.NormalizeJsonString(fmt.Sprintf("{\"policy\": %+s}", string(bodyString)))
 
 	if err := d.Set("policy_id", policyResponse.PolicyID); err != nil {
-		return fmt.Errorf("error setting policy_id: %s", err)
+		return diag.Errorf("error setting policy_id: %s", err)
 	}
 	if err := d.Set("body", bodyStringNormalized); err != nil {
-		return fmt.Errorf("error setting body: %s", err)
+		return diag.Errorf("error setting body: %s", err)
 	}
 	if err := d.Set("primary_term", policyResponse.PrimaryTerm); err != nil {
-		return fmt.Errorf("error setting primary_term: %s", err)
+		return diag.Errorf("error setting primary_term: %s", err)
 	}
 	if err := d.Set("seq_no", policyResponse.SeqNo); err != nil {
-		return fmt.Errorf("error setting seq_no: %s", err)
+		return diag.Errorf("error setting seq_no: %s", err)
 	}
 
 	return nil
 }
 
-func resourceElasticsearchOpenDistroISMPolicyUpdate(d *schema.ResourceData, m interface{}) error {
-	if _, err := resourceElasticsearchPutOpenDistroISMPolicy(d, m); err != nil {
-		return err
+func resourceElasticsearchOpenDistroISMPolicyUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if _, err := resourceElasticsearchPutOpenDistroISMPolicy(ctx, d, m); err != nil {
+		return diag.FromErr(err)
 	}
 
-	return resourceElasticsearchOpenDistroISMPolicyRead(d, m)
+	return resourceElasticsearchOpenDistroISMPolicyRead(ctx, d, m)
 }
 
-func resourceElasticsearchOpenDistroISMPolicyDelete(d *schema.ResourceData, m interface{}) error {
-	path, err := uritemplates.Expand("/_opendistro/_ism/policies/{policy_id}", map[string]string{
+func resourceElasticsearchOpenDistroISMPolicyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	path, err := pluginPath(m, "_ism/policies/{policy_id}", map[string]string{
 		"policy_id": d.Id(),
 	})
 	if err != nil {
-		return fmt.Errorf("error building URL path for policy: %+v", err)
+		return diag.Errorf("error building URL path for policy: %+v", err)
 	}
 
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method:           "DELETE",
 			Path:             path,
 			RetryStatusCodes: []int{http.StatusConflict},
@@ -152,29 +166,29 @@ func resourceElasticsearchOpenDistroISMPolicyDelete(d *schema.ResourceData, m in
 		})
 
 		if err != nil {
-			return fmt.Errorf("error deleting policy: %+v : %+v", path, err)
+			return diag.Errorf("error deleting policy: %+v : %+v", path, err)
 		}
 	case *elastic6.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "DELETE",
 			Path:   path,
 		})
 
 		if err != nil {
-			return fmt.Errorf("error deleting policy: %+v : %+v", path, err)
+			return diag.Errorf("error deleting policy: %+v : %+v", path, err)
 		}
 	default:
 		err = errors.New("policy resource not implemented prior to Elastic v6")
 	}
 
-	return err
+	return diag.FromErr(err)
 }
 
-func resourceElasticsearchGetOpenDistroISMPolicy(policyID string, m interface{}) (GetPolicyResponse, error) {
+func resourceElasticsearchGetOpenDistroISMPolicy(ctx context.Context, policyID string, m interface{}) (GetPolicyResponse, error) {
 	var err error
 	response := new(GetPolicyResponse)
 
-	path, err := uritemplates.Expand("/_opendistro/_ism/policies/{policy_id}", map[string]string{
+	path, err := pluginPath(m, "_ism/policies/{policy_id}", map[string]string{
 		"policy_id": policyID,
 	})
 
@@ -190,7 +204,7 @@ func resourceElasticsearchGetOpenDistroISMPolicy(policyID string, m interface{})
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "GET",
 			Path:   path,
 		})
@@ -201,7 +215,7 @@ func resourceElasticsearchGetOpenDistroISMPolicy(policyID string, m interface{})
 		body = &res.Body
 	case *elastic6.Client:
 		var res *elastic6.Response
-		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "GET",
 			Path:   path,
 		})
@@ -227,7 +241,7 @@ func resourceElasticsearchGetOpenDistroISMPolicy(policyID string, m interface{})
 	return *response, err
 }
 
-func resourceElasticsearchPutOpenDistroISMPolicy(d *schema.ResourceData, m interface{}) (*PutPolicyResponse, error) {
+func resourceElasticsearchPutOpenDistroISMPolicy(ctx context.Context, d *schema.ResourceData, m interface{}) (*PutPolicyResponse, error) {
 	response := new(PutPolicyResponse)
 	policyJSON := d.Get("body").(string)
 	seq := d.Get("seq_no").(int)
@@ -239,7 +253,7 @@ func resourceElasticsearchPutOpenDistroISMPolicy(d *schema.ResourceData, m inter
 		params.Set("if_primary_term", strconv.Itoa(primTerm))
 	}
 
-	path, err := uritemplates.Expand("/_opendistro/_ism/policies/{policy_id}", map[string]string{
+	path, err := pluginPath(m, "_ism/policies/{policy_id}", map[string]string{
 		"policy_id": d.Get("policy_id").(string),
 	})
 	if err != nil {
@@ -254,7 +268,7 @@ func resourceElasticsearchPutOpenDistroISMPolicy(d *schema.ResourceData, m inter
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method:           "PUT",
 			Path:             path,
 			Params:           params,
@@ -270,7 +284,7 @@ func resourceElasticsearchPutOpenDistroISMPolicy(d *schema.ResourceData, m inter
 		body = &res.Body
 	case *elastic6.Client:
 		var res *elastic6.Response
-		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "PUT",
 			Path:   path,
 			Params: params,
@@ -8,9 +8,9 @@ import (
 	"log"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/olivere/elastic/uritemplates"
 
 	elastic7 "github.com/olivere/elastic/v7"
 )
@@ -54,12 +54,13 @@ var openDistroISMPolicyMappingSchema = map[string]*schema.Schema{
 
 func resourceOpenSearchISMPolicyMapping() *schema.Resource {
 	return &schema.Resource{
-		Description: "Provides an Elasticsearch Open Distro Index State Management (ISM) policy. Please refer to the Open Distro [ISM documentation](https://opendistro.github.io/for-elasticsearch-docs/docs/ism/) for details.",
-		Create:      resourceElasticsearchOpenDistroISMPolicyMappingCreate,
-		Read:        resourceElasticsearchOpenDistroISMPolicyMappingRead,
-		Update:      resourceElasticsearchOpenDistroISMPolicyMappingUpdate,
-		Delete:      resourceElasticsearchOpenDistroISMPolicyMappingDelete,
-		Schema:      openDistroISMPolicyMappingSchema,
+		Description:   "Provides an Elasticsearch Open Distro Index State Management (ISM) policy. Please refer to the Open Distro [ISM documentation](https://opendistro.github.io/for-elasticsearch-docs/docs/ism/) for details.",
+		CreateContext: resourceElasticsearchOpenDistroISMPolicyMappingCreate,
+		ReadContext:   resourceElasticsearchOpenDistroISMPolicyMappingRead,
+		UpdateContext: resourceElasticsearchOpenDistroISMPolicyMappingUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroISMPolicyMappingDelete,
+		CustomizeDiff: serverlessCustomizeDiff("elasticsearch_opensearch_ism_policy_mapping"),
+		Schema:        openDistroISMPolicyMappingSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -73,12 +74,13 @@ func resourceOpenSearchISMPolicyMapping() *schema.Resource {
 
 func resourceElasticsearchOpenDistroISMPolicyMapping() *schema.Resource {
 	return &schema.Resource{
-		Description: "Provides an Elasticsearch Open Distro Index State Management (ISM) policy. Please refer to the Open Distro [ISM documentation](https://opendistro.github.io/for-elasticsearch-docs/docs/ism/) for details.",
-		Create:      resourceElasticsearchOpenDistroISMPolicyMappingCreate,
-		Read:        resourceElasticsearchOpenDistroISMPolicyMappingRead,
-		Update:      resourceElasticsearchOpenDistroISMPolicyMappingUpdate,
-		Delete:      resourceElasticsearchOpenDistroISMPolicyMappingDelete,
-		Schema:      openDistroISMPolicyMappingSchema,
+		Description:   "Provides an Elasticsearch Open Distro Index State Management (ISM) policy. Please refer to the Open Distro [ISM documentation](https://opendistro.github.io/for-elasticsearch-docs/docs/ism/) for details.",
+		CreateContext: resourceElasticsearchOpenDistroISMPolicyMappingCreate,
+		ReadContext:   resourceElasticsearchOpenDistroISMPolicyMappingRead,
+		UpdateContext: resourceElasticsearchOpenDistroISMPolicyMappingUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroISMPolicyMappingDelete,
+		CustomizeDiff: serverlessCustomizeDiff("elasticsearch_opendistro_ism_policy_mapping"),
+		Schema:        openDistroISMPolicyMappingSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -90,17 +92,17 @@ func resourceElasticsearchOpenDistroISMPolicyMapping() *schema.Resource {
 	}
 }
 
-func resourceElasticsearchOpenDistroISMPolicyMappingCreate(d *schema.ResourceData, m interface{}) error {
-	resp, err := resourceElasticsearchPostOpendistroPolicyMapping(d, m, "add")
+func resourceElasticsearchOpenDistroISMPolicyMappingCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	resp, err := resourceElasticsearchPostOpendistroPolicyMapping(ctx, d, m, "add")
 	log.Printf("[INFO] resourceElasticsearchOpenDistroISMPolicyMappingCreate %+v", resp)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	indexPattern := d.Get("indexes").(string)
 	policyID := d.Get("policy_id").(string)
 
-	return resource.RetryContext(context.TODO(), d.Timeout(schema.TimeoutCreate), resourceElasticsearchOpenDistroISMPolicyMappingRetry(indexPattern, policyID, d, m))
+	return diag.FromErr(resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), resourceElasticsearchOpenDistroISMPolicyMappingRetry(ctx, indexPattern, policyID, d, m)))
 }
 
 // From https://opendistro.github.io/for-elasticsearch-docs/docs/im/ism/api/#update-managed-index-policy
@@ -112,9 +114,9 @@ func resourceElasticsearchOpenDistroISMPolicyMappingCreate(d *schema.ResourceDat
 // If the change modifies the state, actions, or the order of actions of the
 // current state the index is in, then the change happens at the end of its
 // current state before transitioning to a new state.
-func resourceElasticsearchOpenDistroISMPolicyMappingRetry(indexPattern string, policyID string, d *schema.ResourceData, m interface{}) func() *resource.RetryError {
+func resourceElasticsearchOpenDistroISMPolicyMappingRetry(ctx context.Context, indexPattern string, policyID string, d *schema.ResourceData, m interface{}) func() *resource.RetryError {
 	return func() *resource.RetryError {
-		indices, err := resourceElasticsearchOpendistroPolicyIndices(indexPattern, policyID, m)
+		indices, err := resourceElasticsearchOpendistroPolicyIndices(ctx, indexPattern, policyID, m)
 
 		if err != nil {
 			log.Printf("[INFO] error on retrieving indices %+v", err)
@@ -127,17 +129,17 @@ func resourceElasticsearchOpenDistroISMPolicyMappingRetry(indexPattern string, p
 			return resource.RetryableError(fmt.Errorf("Expected at least one index to be mapped, but found %d", len(indices)))
 		}
 
-		err = resourceElasticsearchOpenDistroISMPolicyMappingRead(d, m)
-		log.Printf("[INFO] resourceElasticsearchOpenDistroISMPolicyMappingRetry error %+v", err)
-		if err != nil {
-			return resource.NonRetryableError(err)
+		diags := resourceElasticsearchOpenDistroISMPolicyMappingRead(ctx, d, m)
+		log.Printf("[INFO] resourceElasticsearchOpenDistroISMPolicyMappingRetry error %+v", diags)
+		if diags.HasError() {
+			return resource.NonRetryableError(errors.New(diags[0].Summary))
 		}
 		return nil
 	}
 }
 
-func resourceElasticsearchOpendistroPolicyIndices(indexPattern string, policyID string, m interface{}) ([]string, error) {
-	indices, err := resourceElasticsearchGetOpendistroPolicyMapping(indexPattern, m)
+func resourceElasticsearchOpendistroPolicyIndices(ctx context.Context, indexPattern string, policyID string, m interface{}) ([]string, error) {
+	indices, err := resourceElasticsearchGetOpendistroPolicyMapping(ctx, indexPattern, m)
 	mappedIndexes := []string{}
 
 	if err != nil {
@@ -157,14 +159,14 @@ func resourceElasticsearchOpendistroPolicyIndices(indexPattern string, policyID
 	return mappedIndexes, nil
 }
 
-func resourceElasticsearchOpenDistroISMPolicyMappingRead(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchOpenDistroISMPolicyMappingRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	indexPattern := d.Get("indexes").(string)
 	policyID := d.Get("policy_id").(string)
 
-	indices, err := resourceElasticsearchOpendistroPolicyIndices(indexPattern, policyID, m)
+	indices, err := resourceElasticsearchOpendistroPolicyIndices(ctx, indexPattern, policyID, m)
 	if err != nil {
 		log.Printf("[INFO] resourceElasticsearchOpenDistroISMPolicyMappingRead %+v %+v", indices, err)
-		return err
+		return diag.FromErr(err)
 	}
 
 	// If there is no managed indices, remove the resource
@@ -179,28 +181,28 @@ func resourceElasticsearchOpenDistroISMPolicyMappingRead(d *schema.ResourceData,
 	ds := &resourceDataSetter{d: d}
 	ds.set("managed_indexes", indices)
 
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func resourceElasticsearchOpenDistroISMPolicyMappingUpdate(d *schema.ResourceData, m interface{}) error {
-	if _, err := resourceElasticsearchPostOpendistroPolicyMapping(d, m, "change_policy"); err != nil {
+func resourceElasticsearchOpenDistroISMPolicyMappingUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if _, err := resourceElasticsearchPostOpendistroPolicyMapping(ctx, d, m, "change_policy"); err != nil {
 		if elastic7.IsNotFound(err) {
 			log.Printf("[WARN] OpendistroPolicyMapping (%s) not found, removing from state", d.Id())
 			d.SetId("")
 			return nil
 		}
-		return err
+		return diag.FromErr(err)
 	}
 
 	indexPattern := d.Get("indexes").(string)
 	policyID := d.Get("policy_id").(string)
 
-	return resource.RetryContext(context.TODO(), d.Timeout(schema.TimeoutUpdate), resourceElasticsearchOpenDistroISMPolicyMappingRetry(indexPattern, policyID, d, m))
+	return diag.FromErr(resource.RetryContext(ctx, d.Timeout(schema.TimeoutUpdate), resourceElasticsearchOpenDistroISMPolicyMappingRetry(ctx, indexPattern, policyID, d, m)))
 }
 
-func resourceElasticsearchOpenDistroISMPolicyMappingDelete(d *schema.ResourceData, m interface{}) error {
-	if _, err := resourceElasticsearchPostOpendistroPolicyMapping(d, m, "remove"); err != nil {
-		return err
+func resourceElasticsearchOpenDistroISMPolicyMappingDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if _, err := resourceElasticsearchPostOpendistroPolicyMapping(ctx, d, m, "remove"); err != nil {
+		return diag.FromErr(err)
 	}
 
 	d.SetId("")
@@ -208,7 +210,7 @@ func resourceElasticsearchOpenDistroISMPolicyMappingDelete(d *schema.ResourceDat
 	return nil
 }
 
-func resourceElasticsearchPostOpendistroPolicyMapping(d *schema.ResourceData, m interface{}, action string) (*PolicyMappingResponse, error) {
+func resourceElasticsearchPostOpendistroPolicyMapping(ctx context.Context, d *schema.ResourceData, m interface{}, action string) (*PolicyMappingResponse, error) {
 	response := new(PolicyMappingResponse)
 	requestBody := ""
 
@@ -240,7 +242,7 @@ func resourceElasticsearchPostOpendistroPolicyMapping(d *schema.ResourceData, m
 
 	}
 
-	path, err := uritemplates.Expand("/_opendistro/_ism/{action}/{indexes}", map[string]string{
+	path, err := pluginPath(m, "_ism/{action}/{indexes}", map[string]string{
 		"indexes": d.Get("indexes").(string),
 		"action":  action,
 	})
@@ -256,7 +258,7 @@ func resourceElasticsearchPostOpendistroPolicyMapping(d *schema.ResourceData, m
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "POST",
 			Path:   path,
 			Body:   requestBody,
@@ -280,9 +282,9 @@ func resourceElasticsearchPostOpendistroPolicyMapping(d *schema.ResourceData, m
 	return response, nil
 }
 
-func resourceElasticsearchGetOpendistroPolicyMapping(indexPattern string, m interface{}) (map[string]interface{}, error) {
+func resourceElasticsearchGetOpendistroPolicyMapping(ctx context.Context, indexPattern string, m interface{}) (map[string]interface{}, error) {
 	response := new(map[string]interface{})
-	path, err := uritemplates.Expand("/_opendistro/_ism/explain/{index_pattern}", map[string]string{
+	path, err := pluginPath(m, "_ism/explain/{index_pattern}", map[string]string{
 		"index_pattern": indexPattern,
 	})
 	if err != nil {
@@ -297,7 +299,7 @@ func resourceElasticsearchGetOpendistroPolicyMapping(indexPattern string, m inte
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "GET",
 			Path:   path,
 		})
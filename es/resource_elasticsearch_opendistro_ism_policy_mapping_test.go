@@ -102,7 +102,7 @@ func indicesMappedToPolicy(policy string) ([]string, error) {
 	}
 	switch esClient.(type) {
 	case *elastic7.Client:
-		indices, err = resourceElasticsearchGetOpendistroPolicyMapping(policy, meta.(*ProviderConf))
+		indices, err = resourceElasticsearchGetOpendistroPolicyMapping(context.TODO(), policy, meta.(*ProviderConf))
 	default:
 	}
 
@@ -89,9 +89,9 @@ func testCheckElasticsearchOpenDistroISMPolicyExists(name string) resource.TestC
 		}
 		switch esClient.(type) {
 		case *elastic7.Client:
-			_, err = resourceElasticsearchGetOpenDistroISMPolicy(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchGetOpenDistroISMPolicy(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 		case *elastic6.Client:
-			_, err = resourceElasticsearchGetOpenDistroISMPolicy(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchGetOpenDistroISMPolicy(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 		default:
 		}
 
@@ -118,9 +118,9 @@ func testCheckElasticsearchOpenDistroISMPolicyDestroy(s *terraform.State) error
 		}
 		switch esClient.(type) {
 		case *elastic7.Client:
-			_, err = resourceElasticsearchGetOpenDistroISMPolicy(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchGetOpenDistroISMPolicy(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 		case *elastic6.Client:
-			_, err = resourceElasticsearchGetOpenDistroISMPolicy(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchGetOpenDistroISMPolicy(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 		default:
 		}
 
@@ -11,8 +11,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/olivere/elastic/uritemplates"
 
 	elastic7 "github.com/olivere/elastic/v7"
 )
@@ -35,44 +35,56 @@ var openDistroKibanaTenantSchema = map[string]*schema.Schema{
 
 func resourceOpenSearchKibanaTenant() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroKibanaTenantCreate,
-		Read:   resourceElasticsearchOpenDistroKibanaTenantRead,
-		Update: resourceElasticsearchOpenDistroKibanaTenantUpdate,
-		Delete: resourceElasticsearchOpenDistroKibanaTenantDelete,
-		Schema: openDistroKibanaTenantSchema,
+		CreateContext: resourceElasticsearchOpenDistroKibanaTenantCreate,
+		ReadContext:   resourceElasticsearchOpenDistroKibanaTenantRead,
+		UpdateContext: resourceElasticsearchOpenDistroKibanaTenantUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroKibanaTenantDelete,
+		Schema:        openDistroKibanaTenantSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
 func resourceElasticsearchOpenDistroKibanaTenant() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroKibanaTenantCreate,
-		Read:   resourceElasticsearchOpenDistroKibanaTenantRead,
-		Update: resourceElasticsearchOpenDistroKibanaTenantUpdate,
-		Delete: resourceElasticsearchOpenDistroKibanaTenantDelete,
-		Schema: openDistroKibanaTenantSchema,
+		CreateContext: resourceElasticsearchOpenDistroKibanaTenantCreate,
+		ReadContext:   resourceElasticsearchOpenDistroKibanaTenantRead,
+		UpdateContext: resourceElasticsearchOpenDistroKibanaTenantUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroKibanaTenantDelete,
+		Schema:        openDistroKibanaTenantSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 		DeprecationMessage: "elasticsearch_opendistro_kibana_tentant is deprecated, please use elasticsearch_opensearch_kibana_tenant resource instead.",
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchOpenDistroKibanaTenantCreate(d *schema.ResourceData, m interface{}) error {
-	if _, err := resourceElasticsearchPutOpenDistroKibanaTenant(d, m); err != nil {
+func resourceElasticsearchOpenDistroKibanaTenantCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if _, err := resourceElasticsearchPutOpenDistroKibanaTenant(ctx, d, m); err != nil {
 		log.Printf("[INFO] Failed to create OpenDistroKibanaTenant: %+v", err)
-		return err
+		return diag.FromErr(err)
 	}
 
 	name := d.Get("tenant_name").(string)
 	d.SetId(name)
-	return resourceElasticsearchOpenDistroKibanaTenantRead(d, m)
+	return resourceElasticsearchOpenDistroKibanaTenantRead(ctx, d, m)
 }
 
-func resourceElasticsearchOpenDistroKibanaTenantRead(d *schema.ResourceData, m interface{}) error {
-	res, err := resourceElasticsearchGetOpenDistroKibanaTenant(d.Id(), m)
+func resourceElasticsearchOpenDistroKibanaTenantRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	res, err := resourceElasticsearchGetOpenDistroKibanaTenant(ctx, d.Id(), m)
 
 	if err != nil {
 		if elastic7.IsNotFound(err) {
@@ -80,22 +92,22 @@ func resourceElasticsearchOpenDistroKibanaTenantRead(d *schema.ResourceData, m i
 			d.SetId("")
 			return nil
 		}
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := d.Set("tenant_name", d.Id()); err != nil {
-		return fmt.Errorf("error setting tenant_name: %s", err)
+		return diag.Errorf("error setting tenant_name: %s", err)
 	}
 	if err := d.Set("description", res.Description); err != nil {
-		return fmt.Errorf("error setting description: %s", err)
+		return diag.Errorf("error setting description: %s", err)
 	}
 
 	index, err := resourceElasticsearchOpenDistroKibanaComputeIndex(d.Id())
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("index", index); err != nil {
-		return fmt.Errorf("error setting index: %s", err)
+		return diag.Errorf("error setting index: %s", err)
 	}
 
 	return nil
@@ -119,29 +131,29 @@ func resourceElasticsearchOpenDistroKibanaComputeIndex(tenant string) (string, e
 	return fmt.Sprintf(".kibana_%v_%v", hashSum, strings.ToLower(cleanedTenant)), nil
 }
 
-func resourceElasticsearchOpenDistroKibanaTenantUpdate(d *schema.ResourceData, m interface{}) error {
-	if _, err := resourceElasticsearchPutOpenDistroKibanaTenant(d, m); err != nil {
-		return err
+func resourceElasticsearchOpenDistroKibanaTenantUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if _, err := resourceElasticsearchPutOpenDistroKibanaTenant(ctx, d, m); err != nil {
+		return diag.FromErr(err)
 	}
 
-	return resourceElasticsearchOpenDistroKibanaTenantRead(d, m)
+	return resourceElasticsearchOpenDistroKibanaTenantRead(ctx, d, m)
 }
 
-func resourceElasticsearchOpenDistroKibanaTenantDelete(d *schema.ResourceData, m interface{}) error {
-	path, err := uritemplates.Expand("/_opendistro/_security/api/tenants/{name}", map[string]string{
+func resourceElasticsearchOpenDistroKibanaTenantDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	path, err := pluginPath(m, "_security/api/tenants/{name}", map[string]string{
 		"name": d.Get("tenant_name").(string),
 	})
 	if err != nil {
-		return fmt.Errorf("error building URL path for tenant: %+v", err)
+		return diag.Errorf("error building URL path for tenant: %+v", err)
 	}
 
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method:           "DELETE",
 			Path:             path,
 			RetryStatusCodes: []int{http.StatusConflict, http.StatusInternalServerError},
@@ -153,14 +165,14 @@ func resourceElasticsearchOpenDistroKibanaTenantDelete(d *schema.ResourceData, m
 		err = errors.New("Creating tenants requires elastic v7 client")
 	}
 
-	return err
+	return diag.FromErr(err)
 }
 
-func resourceElasticsearchGetOpenDistroKibanaTenant(tenantID string, m interface{}) (TenantBody, error) {
+func resourceElasticsearchGetOpenDistroKibanaTenant(ctx context.Context, tenantID string, m interface{}) (TenantBody, error) {
 	var err error
 	tenant := new(TenantBody)
 
-	path, err := uritemplates.Expand("/_opendistro/_security/api/tenants/{name}", map[string]string{
+	path, err := pluginPath(m, "_security/api/tenants/{name}", map[string]string{
 		"name": tenantID,
 	})
 
@@ -176,7 +188,7 @@ func resourceElasticsearchGetOpenDistroKibanaTenant(tenantID string, m interface
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "GET",
 			Path:   path,
 		})
@@ -199,7 +211,7 @@ func resourceElasticsearchGetOpenDistroKibanaTenant(tenantID string, m interface
 	return *tenant, err
 }
 
-func resourceElasticsearchPutOpenDistroKibanaTenant(d *schema.ResourceData, m interface{}) (*TenantResponse, error) {
+func resourceElasticsearchPutOpenDistroKibanaTenant(ctx context.Context, d *schema.ResourceData, m interface{}) (*TenantResponse, error) {
 	response := new(TenantResponse)
 
 	tenantsDefinition := TenantBody{
@@ -211,7 +223,7 @@ func resourceElasticsearchPutOpenDistroKibanaTenant(d *schema.ResourceData, m in
 		return response, fmt.Errorf("Body Error : %s", tenantJSON)
 	}
 
-	path, err := uritemplates.Expand("/_opendistro/_security/api/tenants/{name}", map[string]string{
+	path, err := pluginPath(m, "_security/api/tenants/{name}", map[string]string{
 		"name": d.Get("tenant_name").(string),
 	})
 	if err != nil {
@@ -226,7 +238,7 @@ func resourceElasticsearchPutOpenDistroKibanaTenant(d *schema.ResourceData, m in
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method:           "PUT",
 			Path:             path,
 			Body:             string(tenantJSON),
@@ -90,7 +90,7 @@ func testAccCheckElasticsearchOpenDistroKibanaTenantDestroy(s *terraform.State)
 		}
 		switch esClient.(type) {
 		case *elastic7.Client:
-			_, err = resourceElasticsearchGetOpenDistroKibanaTenant(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchGetOpenDistroKibanaTenant(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 		default:
 		}
 
@@ -119,7 +119,7 @@ func testCheckElasticSearchOpenDistroKibanaTenantExists(name string) resource.Te
 			}
 			switch esClient.(type) {
 			case *elastic7.Client:
-				_, err = resourceElasticsearchGetOpenDistroKibanaTenant(rs.Primary.ID, meta.(*ProviderConf))
+				_, err = resourceElasticsearchGetOpenDistroKibanaTenant(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 			default:
 			}
 
@@ -6,11 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/olivere/elastic/uritemplates"
 
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic6 "gopkg.in/olivere/elastic.v6"
@@ -31,37 +32,49 @@ var openDistroMonitorSchema = map[string]*schema.Schema{
 
 func resourceOpenSearchMonitor() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroMonitorCreate,
-		Read:   resourceElasticsearchOpenDistroMonitorRead,
-		Update: resourceElasticsearchOpenDistroMonitorUpdate,
-		Delete: resourceElasticsearchOpenDistroMonitorDelete,
-		Schema: openDistroMonitorSchema,
+		CreateContext: resourceElasticsearchOpenDistroMonitorCreate,
+		ReadContext:   resourceElasticsearchOpenDistroMonitorRead,
+		UpdateContext: resourceElasticsearchOpenDistroMonitorUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroMonitorDelete,
+		Schema:        openDistroMonitorSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
 func resourceElasticsearchOpenDistroMonitor() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroMonitorCreate,
-		Read:   resourceElasticsearchOpenDistroMonitorRead,
-		Update: resourceElasticsearchOpenDistroMonitorUpdate,
-		Delete: resourceElasticsearchOpenDistroMonitorDelete,
-		Schema: openDistroMonitorSchema,
+		CreateContext: resourceElasticsearchOpenDistroMonitorCreate,
+		ReadContext:   resourceElasticsearchOpenDistroMonitorRead,
+		UpdateContext: resourceElasticsearchOpenDistroMonitorUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroMonitorDelete,
+		Schema:        openDistroMonitorSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 		DeprecationMessage: "elasticsearch_opendistro_monitor is deprecated, please use elasticsearch_opensearch_monitor resource instead.",
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchOpenDistroMonitorCreate(d *schema.ResourceData, m interface{}) error {
-	res, err := resourceElasticsearchOpenDistroPostMonitor(d, m)
+func resourceElasticsearchOpenDistroMonitorCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	res, err := resourceElasticsearchOpenDistroPostMonitor(ctx, d, m)
 
 	if err != nil {
 		log.Printf("[INFO] Failed to put monitor: %+v", err)
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(res.ID)
@@ -70,11 +83,11 @@ func resourceElasticsearchOpenDistroMonitorCreate(d *schema.ResourceData, m inte
 	// Although we receive the full monitor in the response to the POST,
 	// OpenDistro seems to add default values to the ojbect after the resource
 	// is saved, e.g. adjust_pure_negative, boost values
-	return resourceElasticsearchOpenDistroMonitorRead(d, m)
+	return resourceElasticsearchOpenDistroMonitorRead(ctx, d, m)
 }
 
-func resourceElasticsearchOpenDistroMonitorRead(d *schema.ResourceData, m interface{}) error {
-	res, err := resourceElasticsearchOpenDistroGetMonitor(d.Id(), m)
+func resourceElasticsearchOpenDistroMonitorRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	res, err := resourceElasticsearchOpenDistroGetMonitor(ctx, d.Id(), m)
 
 	if elastic6.IsNotFound(err) || elastic7.IsNotFound(err) {
 		log.Printf("[WARN] Monitor (%s) not found, removing from state", d.Id())
@@ -83,55 +96,55 @@ func resourceElasticsearchOpenDistroMonitorRead(d *schema.ResourceData, m interf
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(res.ID)
 
 	monitorJson, err := json.Marshal(res.Monitor)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	monitorJsonNormalized, err := structure.NormalizeJsonString(string(monitorJson))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	err = d.Set("body", monitorJsonNormalized)
-	return err
+	return diag.FromErr(err)
 }
 
-func resourceElasticsearchOpenDistroMonitorUpdate(d *schema.ResourceData, m interface{}) error {
-	_, err := resourceElasticsearchOpenDistroPutMonitor(d, m)
+func resourceElasticsearchOpenDistroMonitorUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	_, err := resourceElasticsearchOpenDistroPutMonitor(ctx, d, m)
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceElasticsearchOpenDistroMonitorRead(d, m)
+	return resourceElasticsearchOpenDistroMonitorRead(ctx, d, m)
 }
 
-func resourceElasticsearchOpenDistroMonitorDelete(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchOpenDistroMonitorDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var err error
 
-	path, err := uritemplates.Expand("/_opendistro/_alerting/monitors/{id}", map[string]string{
+	path, err := pluginPath(m, "_alerting/monitors/{id}", map[string]string{
 		"id": d.Id(),
 	})
 	if err != nil {
-		return fmt.Errorf("error building URL path for monitor: %+v", err)
+		return diag.Errorf("error building URL path for monitor: %+v", err)
 	}
 
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "DELETE",
 			Path:   path,
 		})
 	case *elastic6.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "DELETE",
 			Path:   path,
 		})
@@ -139,14 +152,14 @@ func resourceElasticsearchOpenDistroMonitorDelete(d *schema.ResourceData, m inte
 		err = errors.New("monitor resource not implemented prior to Elastic v6")
 	}
 
-	return err
+	return diag.FromErr(err)
 }
 
-func resourceElasticsearchOpenDistroGetMonitor(monitorID string, m interface{}) (*monitorResponse, error) {
+func resourceElasticsearchOpenDistroGetMonitor(ctx context.Context, monitorID string, m interface{}) (*monitorResponse, error) {
 	var err error
 	response := new(monitorResponse)
 
-	path, err := uritemplates.Expand("/_opendistro/_alerting/monitors/{id}", map[string]string{
+	path, err := pluginPath(m, "_alerting/monitors/{id}", map[string]string{
 		"id": monitorID,
 	})
 	if err != nil {
@@ -161,7 +174,7 @@ func resourceElasticsearchOpenDistroGetMonitor(monitorID string, m interface{})
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "GET",
 			Path:   path,
 		})
@@ -171,7 +184,7 @@ func resourceElasticsearchOpenDistroGetMonitor(monitorID string, m interface{})
 		body = res.Body
 	case *elastic6.Client:
 		var res *elastic6.Response
-		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "GET",
 			Path:   path,
 		})
@@ -190,13 +203,16 @@ func resourceElasticsearchOpenDistroGetMonitor(monitorID string, m interface{})
 	return response, err
 }
 
-func resourceElasticsearchOpenDistroPostMonitor(d *schema.ResourceData, m interface{}) (*monitorResponse, error) {
+func resourceElasticsearchOpenDistroPostMonitor(ctx context.Context, d *schema.ResourceData, m interface{}) (*monitorResponse, error) {
 	monitorJSON := d.Get("body").(string)
 
 	var err error
 	response := new(monitorResponse)
 
-	path := "/_opendistro/_alerting/monitors/"
+	path, err := pluginPath(m, "_alerting/monitors/", nil)
+	if err != nil {
+		return response, fmt.Errorf("error building URL path for monitor: %+v", err)
+	}
 
 	var body json.RawMessage
 	esClient, err := getClient(m.(*ProviderConf))
@@ -206,7 +222,7 @@ func resourceElasticsearchOpenDistroPostMonitor(d *schema.ResourceData, m interf
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "POST",
 			Path:   path,
 			Body:   monitorJSON,
@@ -217,7 +233,7 @@ func resourceElasticsearchOpenDistroPostMonitor(d *schema.ResourceData, m interf
 		body = res.Body
 	case *elastic6.Client:
 		var res *elastic6.Response
-		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "POST",
 			Path:   path,
 			Body:   monitorJSON,
@@ -237,13 +253,13 @@ func resourceElasticsearchOpenDistroPostMonitor(d *schema.ResourceData, m interf
 	return response, nil
 }
 
-func resourceElasticsearchOpenDistroPutMonitor(d *schema.ResourceData, m interface{}) (*monitorResponse, error) {
+func resourceElasticsearchOpenDistroPutMonitor(ctx context.Context, d *schema.ResourceData, m interface{}) (*monitorResponse, error) {
 	monitorJSON := d.Get("body").(string)
 
 	var err error
 	response := new(monitorResponse)
 
-	path, err := uritemplates.Expand("/_opendistro/_alerting/monitors/{id}", map[string]string{
+	path, err := pluginPath(m, "_alerting/monitors/{id}", map[string]string{
 		"id": d.Id(),
 	})
 	if err != nil {
@@ -258,7 +274,7 @@ func resourceElasticsearchOpenDistroPutMonitor(d *schema.ResourceData, m interfa
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "PUT",
 			Path:   path,
 			Body:   monitorJSON,
@@ -269,7 +285,7 @@ func resourceElasticsearchOpenDistroPutMonitor(d *schema.ResourceData, m interfa
 		body = res.Body
 	case *elastic6.Client:
 		var res *elastic6.Response
-		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "PUT",
 			Path:   path,
 			Body:   monitorJSON,
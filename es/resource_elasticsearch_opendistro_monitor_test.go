@@ -1,6 +1,7 @@
 package es
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -68,9 +69,9 @@ func testCheckElasticsearchOpenDistroMonitorExists(name string) resource.TestChe
 		}
 		switch esClient.(type) {
 		case *elastic7.Client:
-			_, err = resourceElasticsearchOpenDistroGetMonitor(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchOpenDistroGetMonitor(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 		case *elastic6.Client:
-			_, err = resourceElasticsearchOpenDistroGetMonitor(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchOpenDistroGetMonitor(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 		default:
 		}
 
@@ -97,10 +98,10 @@ func testCheckElasticsearchMonitorDestroy(s *terraform.State) error {
 		}
 		switch esClient.(type) {
 		case *elastic7.Client:
-			_, err = resourceElasticsearchOpenDistroGetMonitor(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchOpenDistroGetMonitor(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 
 		case *elastic6.Client:
-			_, err = resourceElasticsearchOpenDistroGetMonitor(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchOpenDistroGetMonitor(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 		default:
 		}
 
@@ -9,8 +9,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/olivere/elastic/uritemplates"
 
 	elastic7 "github.com/olivere/elastic/v7"
 )
@@ -104,44 +104,56 @@ var openDistroRoleSchema = map[string]*schema.Schema{
 
 func resourceOpenSearchRole() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroRoleCreate,
-		Read:   resourceElasticsearchOpenDistroRoleRead,
-		Update: resourceElasticsearchOpenDistroRoleUpdate,
-		Delete: resourceElasticsearchOpenDistroRoleDelete,
-		Schema: openDistroRoleSchema,
+		CreateContext: resourceElasticsearchOpenDistroRoleCreate,
+		ReadContext:   resourceElasticsearchOpenDistroRoleRead,
+		UpdateContext: resourceElasticsearchOpenDistroRoleUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroRoleDelete,
+		Schema:        openDistroRoleSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
 func resourceElasticsearchOpenDistroRole() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroRoleCreate,
-		Read:   resourceElasticsearchOpenDistroRoleRead,
-		Update: resourceElasticsearchOpenDistroRoleUpdate,
-		Delete: resourceElasticsearchOpenDistroRoleDelete,
-		Schema: openDistroRoleSchema,
+		CreateContext: resourceElasticsearchOpenDistroRoleCreate,
+		ReadContext:   resourceElasticsearchOpenDistroRoleRead,
+		UpdateContext: resourceElasticsearchOpenDistroRoleUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroRoleDelete,
+		Schema:        openDistroRoleSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 		DeprecationMessage: "elasticsearch_opendistro_role is deprecated, please use elasticsearch_opensearch_role resource instead.",
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchOpenDistroRoleCreate(d *schema.ResourceData, m interface{}) error {
-	if _, err := resourceElasticsearchPutOpenDistroRole(d, m); err != nil {
+func resourceElasticsearchOpenDistroRoleCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if _, err := resourceElasticsearchPutOpenDistroRole(ctx, d, m); err != nil {
 		log.Printf("[INFO] Failed to create OpenDistroRole: %+v", err)
-		return err
+		return diag.FromErr(err)
 	}
 
 	name := d.Get("role_name").(string)
 	d.SetId(name)
-	return resourceElasticsearchOpenDistroRoleRead(d, m)
+	return resourceElasticsearchOpenDistroRoleRead(ctx, d, m)
 }
 
-func resourceElasticsearchOpenDistroRoleRead(d *schema.ResourceData, m interface{}) error {
-	res, err := resourceElasticsearchGetOpenDistroRole(d.Id(), m)
+func resourceElasticsearchOpenDistroRoleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	res, err := resourceElasticsearchGetOpenDistroRole(ctx, d.Id(), m)
 
 	if err != nil {
 		if elastic7.IsNotFound(err) {
@@ -149,51 +161,51 @@ func resourceElasticsearchOpenDistroRoleRead(d *schema.ResourceData, m interface
 			d.SetId("")
 			return nil
 		}
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := d.Set("role_name", d.Id()); err != nil {
-		return fmt.Errorf("error setting role_name: %s", err)
+		return diag.Errorf("error setting role_name: %s", err)
 	}
 	if err := d.Set("tenant_permissions", flattenTenantPermissions(res.TenantPermissions)); err != nil {
-		return fmt.Errorf("error setting tenant_permissions: %s", err)
+		return diag.Errorf("error setting tenant_permissions: %s", err)
 	}
 	if err := d.Set("cluster_permissions", res.ClusterPermissions); err != nil {
-		return fmt.Errorf("error setting cluster_permissions: %s", err)
+		return diag.Errorf("error setting cluster_permissions: %s", err)
 	}
 	if err := d.Set("index_permissions", flattenIndexPermissions(res.IndexPermissions, d)); err != nil {
-		return fmt.Errorf("error setting index_permissions: %s", err)
+		return diag.Errorf("error setting index_permissions: %s", err)
 	}
 	if err := d.Set("description", res.Description); err != nil {
-		return fmt.Errorf("error setting description: %s", err)
+		return diag.Errorf("error setting description: %s", err)
 	}
 
 	return nil
 }
 
-func resourceElasticsearchOpenDistroRoleUpdate(d *schema.ResourceData, m interface{}) error {
-	if _, err := resourceElasticsearchPutOpenDistroRole(d, m); err != nil {
-		return err
+func resourceElasticsearchOpenDistroRoleUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if _, err := resourceElasticsearchPutOpenDistroRole(ctx, d, m); err != nil {
+		return diag.FromErr(err)
 	}
 
-	return resourceElasticsearchOpenDistroRoleRead(d, m)
+	return resourceElasticsearchOpenDistroRoleRead(ctx, d, m)
 }
 
-func resourceElasticsearchOpenDistroRoleDelete(d *schema.ResourceData, m interface{}) error {
-	path, err := uritemplates.Expand("/_opendistro/_security/api/roles/{name}", map[string]string{
+func resourceElasticsearchOpenDistroRoleDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	path, err := pluginPath(m, "_security/api/roles/{name}", map[string]string{
 		"name": d.Get("role_name").(string),
 	})
 	if err != nil {
-		return fmt.Errorf("error building URL path for role: %+v", err)
+		return diag.Errorf("error building URL path for role: %+v", err)
 	}
 
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method:           "DELETE",
 			Path:             path,
 			RetryStatusCodes: []int{http.StatusConflict, http.StatusInternalServerError},
@@ -205,14 +217,14 @@ func resourceElasticsearchOpenDistroRoleDelete(d *schema.ResourceData, m interfa
 		err = errors.New("role resource not implemented prior to Elastic v7")
 	}
 
-	return err
+	return diag.FromErr(err)
 }
 
-func resourceElasticsearchGetOpenDistroRole(roleID string, m interface{}) (RoleBody, error) {
+func resourceElasticsearchGetOpenDistroRole(ctx context.Context, roleID string, m interface{}) (RoleBody, error) {
 	var err error
 	role := new(RoleBody)
 
-	path, err := uritemplates.Expand("/_opendistro/_security/api/roles/{name}", map[string]string{
+	path, err := pluginPath(m, "_security/api/roles/{name}", map[string]string{
 		"name": roleID,
 	})
 
@@ -228,7 +240,7 @@ func resourceElasticsearchGetOpenDistroRole(roleID string, m interface{}) (RoleB
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "GET",
 			Path:   path,
 		})
@@ -251,7 +263,7 @@ func resourceElasticsearchGetOpenDistroRole(roleID string, m interface{}) (RoleB
 	return *role, err
 }
 
-func resourceElasticsearchPutOpenDistroRole(d *schema.ResourceData, m interface{}) (*RoleResponse, error) {
+func resourceElasticsearchPutOpenDistroRole(ctx context.Context, d *schema.ResourceData, m interface{}) (*RoleResponse, error) {
 	response := new(RoleResponse)
 
 	indexPermissions, err := expandIndexPermissionsSet(d.Get("index_permissions").(*schema.Set).List())
@@ -295,7 +307,7 @@ func resourceElasticsearchPutOpenDistroRole(d *schema.ResourceData, m interface{
 		return response, fmt.Errorf("Body Error : %s", roleJSON)
 	}
 
-	path, err := uritemplates.Expand("/_opendistro/_security/api/roles/{name}", map[string]string{
+	path, err := pluginPath(m, "_security/api/roles/{name}", map[string]string{
 		"name": d.Get("role_name").(string),
 	})
 	if err != nil {
@@ -310,7 +322,7 @@ func resourceElasticsearchPutOpenDistroRole(d *schema.ResourceData, m interface{
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "PUT",
 			Path:   path,
 			Body:   string(roleJSON),
@@ -177,7 +177,7 @@ func testAccCheckElasticsearchOpenDistroRoleDestroy(s *terraform.State) error {
 		}
 		switch esClient.(type) {
 		case *elastic7.Client:
-			_, err = resourceElasticsearchGetOpenDistroRole(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchGetOpenDistroRole(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 		default:
 		}
 
@@ -206,7 +206,7 @@ func testCheckElasticSearchOpenDistroRoleExists(name string) resource.TestCheckF
 			}
 			switch esClient.(type) {
 			case *elastic7.Client:
-				_, err = resourceElasticsearchGetOpenDistroRole(rs.Primary.ID, meta.(*ProviderConf))
+				_, err = resourceElasticsearchGetOpenDistroRole(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 			default:
 			}
 
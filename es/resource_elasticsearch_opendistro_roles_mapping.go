@@ -9,8 +9,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/olivere/elastic/uritemplates"
 
 	elastic7 "github.com/olivere/elastic/v7"
 )
@@ -49,44 +49,56 @@ var openDistroRolesMappingSchema = map[string]*schema.Schema{
 
 func resourceOpenSearchRolesMapping() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroRolesMappingCreate,
-		Read:   resourceElasticsearchOpenDistroRolesMappingRead,
-		Update: resourceElasticsearchOpenDistroRolesMappingUpdate,
-		Delete: resourceElasticsearchOpenDistroRolesMappingDelete,
-		Schema: openDistroRolesMappingSchema,
+		CreateContext: resourceElasticsearchOpenDistroRolesMappingCreate,
+		ReadContext:   resourceElasticsearchOpenDistroRolesMappingRead,
+		UpdateContext: resourceElasticsearchOpenDistroRolesMappingUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroRolesMappingDelete,
+		Schema:        openDistroRolesMappingSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
 func resourceElasticsearchOpenDistroRolesMapping() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroRolesMappingCreate,
-		Read:   resourceElasticsearchOpenDistroRolesMappingRead,
-		Update: resourceElasticsearchOpenDistroRolesMappingUpdate,
-		Delete: resourceElasticsearchOpenDistroRolesMappingDelete,
-		Schema: openDistroRolesMappingSchema,
+		CreateContext: resourceElasticsearchOpenDistroRolesMappingCreate,
+		ReadContext:   resourceElasticsearchOpenDistroRolesMappingRead,
+		UpdateContext: resourceElasticsearchOpenDistroRolesMappingUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroRolesMappingDelete,
+		Schema:        openDistroRolesMappingSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 		DeprecationMessage: "elasticsearch_opendistro_roles_mapping is deprecated, please use elasticsearch_opensearch_roles_mapping resource instead.",
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchOpenDistroRolesMappingCreate(d *schema.ResourceData, m interface{}) error {
-	if _, err := resourceElasticsearchPutOpenDistroRolesMapping(d, m); err != nil {
+func resourceElasticsearchOpenDistroRolesMappingCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if _, err := resourceElasticsearchPutOpenDistroRolesMapping(ctx, d, m); err != nil {
 		log.Printf("[INFO] Failed to put role mapping: %+v", err)
-		return err
+		return diag.FromErr(err)
 	}
 
 	name := d.Get("role_name").(string)
 	d.SetId(name)
-	return resourceElasticsearchOpenDistroRolesMappingRead(d, m)
+	return resourceElasticsearchOpenDistroRolesMappingRead(ctx, d, m)
 }
 
-func resourceElasticsearchOpenDistroRolesMappingRead(d *schema.ResourceData, m interface{}) error {
-	res, err := resourceElasticsearchGetOpenDistroRolesMapping(d.Id(), m)
+func resourceElasticsearchOpenDistroRolesMappingRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	res, err := resourceElasticsearchGetOpenDistroRolesMapping(ctx, d.Id(), m)
 
 	if err != nil {
 		if elastic7.IsNotFound(err) {
@@ -94,54 +106,54 @@ func resourceElasticsearchOpenDistroRolesMappingRead(d *schema.ResourceData, m i
 			d.SetId("")
 			return nil
 		}
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := d.Set("role_name", d.Id()); err != nil {
-		return fmt.Errorf("error setting role_name: %s", err)
+		return diag.Errorf("error setting role_name: %s", err)
 	}
 	if err := d.Set("backend_roles", res.BackendRoles); err != nil {
-		return fmt.Errorf("error setting backend_roles: %s", err)
+		return diag.Errorf("error setting backend_roles: %s", err)
 	}
 	if err := d.Set("hosts", res.Hosts); err != nil {
-		return fmt.Errorf("error setting hosts: %s", err)
+		return diag.Errorf("error setting hosts: %s", err)
 	}
 	if err := d.Set("users", res.Users); err != nil {
-		return fmt.Errorf("error setting users: %s", err)
+		return diag.Errorf("error setting users: %s", err)
 	}
 	if err := d.Set("description", res.Description); err != nil {
-		return fmt.Errorf("error setting description: %s", err)
+		return diag.Errorf("error setting description: %s", err)
 	}
 	if err := d.Set("and_backend_roles", res.AndBackendRoles); err != nil {
-		return fmt.Errorf("error setting and_backend_roles: %s", err)
+		return diag.Errorf("error setting and_backend_roles: %s", err)
 	}
 
 	return nil
 }
 
-func resourceElasticsearchOpenDistroRolesMappingUpdate(d *schema.ResourceData, m interface{}) error {
-	if _, err := resourceElasticsearchPutOpenDistroRolesMapping(d, m); err != nil {
-		return err
+func resourceElasticsearchOpenDistroRolesMappingUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if _, err := resourceElasticsearchPutOpenDistroRolesMapping(ctx, d, m); err != nil {
+		return diag.FromErr(err)
 	}
 
-	return resourceElasticsearchOpenDistroRolesMappingRead(d, m)
+	return resourceElasticsearchOpenDistroRolesMappingRead(ctx, d, m)
 }
 
-func resourceElasticsearchOpenDistroRolesMappingDelete(d *schema.ResourceData, m interface{}) error {
-	path, err := uritemplates.Expand("/_opendistro/_security/api/rolesmapping/{name}", map[string]string{
+func resourceElasticsearchOpenDistroRolesMappingDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	path, err := pluginPath(m, "_security/api/rolesmapping/{name}", map[string]string{
 		"name": d.Get("role_name").(string),
 	})
 	if err != nil {
-		return fmt.Errorf("error building URL path for role mapping: %+v", err)
+		return diag.Errorf("error building URL path for role mapping: %+v", err)
 	}
 
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method:           "DELETE",
 			Path:             path,
 			RetryStatusCodes: []int{http.StatusConflict, http.StatusInternalServerError},
@@ -153,14 +165,14 @@ func resourceElasticsearchOpenDistroRolesMappingDelete(d *schema.ResourceData, m
 		err = errors.New("role mapping resource not implemented prior to Elastic v7")
 	}
 
-	return err
+	return diag.FromErr(err)
 }
 
-func resourceElasticsearchGetOpenDistroRolesMapping(roleID string, m interface{}) (RolesMapping, error) {
+func resourceElasticsearchGetOpenDistroRolesMapping(ctx context.Context, roleID string, m interface{}) (RolesMapping, error) {
 	var err error
 	var roleMapping = new(RolesMapping)
 
-	path, err := uritemplates.Expand("/_opendistro/_security/api/rolesmapping/{name}", map[string]string{
+	path, err := pluginPath(m, "_security/api/rolesmapping/{name}", map[string]string{
 		"name": roleID,
 	})
 
@@ -175,7 +187,7 @@ func resourceElasticsearchGetOpenDistroRolesMapping(roleID string, m interface{}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "GET",
 			Path:   path,
 		})
@@ -201,7 +213,7 @@ func resourceElasticsearchGetOpenDistroRolesMapping(roleID string, m interface{}
 	return *roleMapping, err
 }
 
-func resourceElasticsearchPutOpenDistroRolesMapping(d *schema.ResourceData, m interface{}) (*RoleMappingResponse, error) {
+func resourceElasticsearchPutOpenDistroRolesMapping(ctx context.Context, d *schema.ResourceData, m interface{}) (*RoleMappingResponse, error) {
 	var err error
 	response := new(RoleMappingResponse)
 
@@ -218,7 +230,7 @@ func resourceElasticsearchPutOpenDistroRolesMapping(d *schema.ResourceData, m in
 		return response, fmt.Errorf("Body Error : %s", roleJSON)
 	}
 
-	path, err := uritemplates.Expand("/_opendistro/_security/api/rolesmapping/{name}", map[string]string{
+	path, err := pluginPath(m, "_security/api/rolesmapping/{name}", map[string]string{
 		"name": d.Get("role_name").(string),
 	})
 
@@ -234,7 +246,7 @@ func resourceElasticsearchPutOpenDistroRolesMapping(d *schema.ResourceData, m in
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "PUT",
 			Path:   path,
 			Body:   string(roleJSON),
@@ -95,7 +95,7 @@ func testAccCheckElasticsearchOpenDistroRolesMappingDestroy(s *terraform.State)
 		}
 		switch esClient.(type) {
 		case *elastic7.Client:
-			_, err = resourceElasticsearchGetOpenDistroRolesMapping(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchGetOpenDistroRolesMapping(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 		default:
 		}
 
@@ -124,7 +124,7 @@ func testCheckElasticSearchOpenDistroRolesMappingExists(name string) resource.Te
 			}
 			switch esClient.(type) {
 			case *elastic7.Client:
-				_, err = resourceElasticsearchGetOpenDistroRolesMapping(rs.Primary.ID, meta.(*ProviderConf))
+				_, err = resourceElasticsearchGetOpenDistroRolesMapping(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 			default:
 			}
 
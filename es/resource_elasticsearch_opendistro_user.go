@@ -9,8 +9,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/olivere/elastic/uritemplates"
 
 	elastic7 "github.com/olivere/elastic/v7"
 )
@@ -52,45 +52,57 @@ var openDistroUserSchema = map[string]*schema.Schema{
 
 func resourceOpenSearchUser() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroUserCreate,
-		Read:   resourceElasticsearchOpenDistroUserRead,
-		Update: resourceElasticsearchOpenDistroUserUpdate,
-		Delete: resourceElasticsearchOpenDistroUserDelete,
-		Schema: openDistroUserSchema,
+		CreateContext: resourceElasticsearchOpenDistroUserCreate,
+		ReadContext:   resourceElasticsearchOpenDistroUserRead,
+		UpdateContext: resourceElasticsearchOpenDistroUserUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroUserDelete,
+		Schema:        openDistroUserSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
 func resourceElasticsearchOpenDistroUser() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroUserCreate,
-		Read:   resourceElasticsearchOpenDistroUserRead,
-		Update: resourceElasticsearchOpenDistroUserUpdate,
-		Delete: resourceElasticsearchOpenDistroUserDelete,
-		Schema: openDistroUserSchema,
+		CreateContext: resourceElasticsearchOpenDistroUserCreate,
+		ReadContext:   resourceElasticsearchOpenDistroUserRead,
+		UpdateContext: resourceElasticsearchOpenDistroUserUpdate,
+		DeleteContext: resourceElasticsearchOpenDistroUserDelete,
+		Schema:        openDistroUserSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 		DeprecationMessage: "elasticsearch_opendistro_user is deprecated, please use elasticsearch_opensearch_user resource instead.",
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchOpenDistroUserCreate(d *schema.ResourceData, m interface{}) error {
-	_, err := resourceElasticsearchPutOpenDistroUser(d, m)
+func resourceElasticsearchOpenDistroUserCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	_, err := resourceElasticsearchPutOpenDistroUser(ctx, d, m)
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	name := d.Get("username").(string)
 	d.SetId(name)
-	return resourceElasticsearchOpenDistroUserRead(d, m)
+	return resourceElasticsearchOpenDistroUserRead(ctx, d, m)
 }
 
-func resourceElasticsearchOpenDistroUserRead(d *schema.ResourceData, m interface{}) error {
-	res, err := resourceElasticsearchGetOpenDistroUser(d.Id(), m)
+func resourceElasticsearchOpenDistroUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	res, err := resourceElasticsearchGetOpenDistroUser(ctx, d.Id(), m)
 
 	if err != nil {
 		if elastic7.IsNotFound(err) {
@@ -98,41 +110,41 @@ func resourceElasticsearchOpenDistroUserRead(d *schema.ResourceData, m interface
 			d.SetId("")
 			return nil
 		}
-		return err
+		return diag.FromErr(err)
 	}
 
 	ds := &resourceDataSetter{d: d}
 	ds.set("backend_roles", res.BackendRoles)
 	ds.set("attributes", res.Attributes)
 	ds.set("description", res.Description)
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func resourceElasticsearchOpenDistroUserUpdate(d *schema.ResourceData, m interface{}) error {
-	if _, err := resourceElasticsearchPutOpenDistroUser(d, m); err != nil {
-		return err
+func resourceElasticsearchOpenDistroUserUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if _, err := resourceElasticsearchPutOpenDistroUser(ctx, d, m); err != nil {
+		return diag.FromErr(err)
 	}
 
-	return resourceElasticsearchOpenDistroUserRead(d, m)
+	return resourceElasticsearchOpenDistroUserRead(ctx, d, m)
 }
 
-func resourceElasticsearchOpenDistroUserDelete(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchOpenDistroUserDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var err error
 
-	path, err := uritemplates.Expand("/_opendistro/_security/api/internalusers/{name}", map[string]string{
+	path, err := pluginPath(m, "_security/api/internalusers/{name}", map[string]string{
 		"name": d.Get("username").(string),
 	})
 	if err != nil {
-		return fmt.Errorf("Error building URL path for user: %+v", err)
+		return diag.Errorf("Error building URL path for user: %+v", err)
 	}
 
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method:           "DELETE",
 			Path:             path,
 			RetryStatusCodes: []int{http.StatusConflict, http.StatusInternalServerError},
@@ -144,14 +156,14 @@ func resourceElasticsearchOpenDistroUserDelete(d *schema.ResourceData, m interfa
 		err = errors.New("Role resource not implemented prior to Elastic v7")
 	}
 
-	return err
+	return diag.FromErr(err)
 }
 
-func resourceElasticsearchGetOpenDistroUser(userID string, m interface{}) (UserBody, error) {
+func resourceElasticsearchGetOpenDistroUser(ctx context.Context, userID string, m interface{}) (UserBody, error) {
 	var err error
 	user := new(UserBody)
 
-	path, err := uritemplates.Expand("/_opendistro/_security/api/internalusers/{name}", map[string]string{
+	path, err := pluginPath(m, "_security/api/internalusers/{name}", map[string]string{
 		"name": userID,
 	})
 
@@ -167,7 +179,7 @@ func resourceElasticsearchGetOpenDistroUser(userID string, m interface{}) (UserB
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "GET",
 			Path:   path,
 		})
@@ -190,7 +202,7 @@ func resourceElasticsearchGetOpenDistroUser(userID string, m interface{}) (UserB
 	return *user, err
 }
 
-func resourceElasticsearchPutOpenDistroUser(d *schema.ResourceData, m interface{}) (*UserResponse, error) {
+func resourceElasticsearchPutOpenDistroUser(ctx context.Context, d *schema.ResourceData, m interface{}) (*UserResponse, error) {
 	response := new(UserResponse)
 
 	userDefinition := UserBody{
@@ -211,7 +223,7 @@ func resourceElasticsearchPutOpenDistroUser(d *schema.ResourceData, m interface{
 		return response, fmt.Errorf("Body Error : %s", userJSON)
 	}
 
-	path, err := uritemplates.Expand("/_opendistro/_security/api/internalusers/{name}", map[string]string{
+	path, err := pluginPath(m, "_security/api/internalusers/{name}", map[string]string{
 		"name": d.Get("username").(string),
 	})
 	if err != nil {
@@ -227,7 +239,7 @@ func resourceElasticsearchPutOpenDistroUser(d *schema.ResourceData, m interface{
 	case *elastic7.Client:
 		var res *elastic7.Response
 		log.Printf("[INFO] put opendistro user: %+v", userDefinition)
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "PUT",
 			Path:   path,
 			Body:   string(userJSON),
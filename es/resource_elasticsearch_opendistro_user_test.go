@@ -166,7 +166,7 @@ func testAccCheckElasticsearchOpenDistroUserDestroy(s *terraform.State) error {
 		}
 		switch esClient.(type) {
 		case *elastic7.Client:
-			_, err = resourceElasticsearchGetOpenDistroUser(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchGetOpenDistroUser(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 		default:
 		}
 
@@ -196,7 +196,7 @@ func testCheckElasticSearchOpenDistroUserExists(name string) resource.TestCheckF
 			}
 			switch esClient.(type) {
 			case *elastic7.Client:
-				_, err = resourceElasticsearchGetOpenDistroUser(rs.Primary.ID, meta.(*ProviderConf))
+				_, err = resourceElasticsearchGetOpenDistroUser(context.TODO(), rs.Primary.ID, meta.(*ProviderConf))
 			default:
 			}
 
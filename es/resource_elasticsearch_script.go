@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -35,14 +37,20 @@ var scriptSchema = map[string]*schema.Schema{
 
 func resourceElasticsearchScript() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchScriptCreate,
-		Read:   resourceElasticsearchScriptRead,
-		Update: resourceElasticsearchScriptUpdate,
-		Delete: resourceElasticsearchScriptDelete,
-		Schema: scriptSchema,
+		CreateContext: resourceElasticsearchScriptCreate,
+		ReadContext:   resourceElasticsearchScriptRead,
+		UpdateContext: resourceElasticsearchScriptUpdate,
+		DeleteContext: resourceElasticsearchScriptDelete,
+		Schema:        scriptSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
@@ -64,34 +72,34 @@ func buildScriptJSONBody(d *schema.ResourceData) (string, error) {
 	return string(data), nil
 }
 
-func resourceElasticsearchScriptCreate(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchScriptCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	// Determine whether the script already exists, otherwise the API will
 	// override an existing script with the name.
 	scriptID := d.Get("script_id").(string)
-	_, err := resourceElasticsearchGetScript(scriptID, m)
+	_, err := resourceElasticsearchGetScript(ctx, scriptID, m)
 
 	if err == nil {
 		log.Printf("[INFO] script exists: %+v", err)
-		return fmt.Errorf("script already exists with ID: %v", scriptID)
+		return diag.Errorf("script already exists with ID: %v", scriptID)
 	} else if err != nil && !elastic6.IsNotFound(err) && !elastic7.IsNotFound(err) {
-		return err
+		return diag.FromErr(err)
 	}
 
-	scriptID, err = resourceElasticsearchPutScript(d, m)
+	scriptID, err = resourceElasticsearchPutScript(ctx, d, m)
 
 	if err != nil {
 		log.Printf("[INFO] Failed to put script: %+v", err)
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(scriptID)
 	log.Printf("[INFO] Object ID: %s", d.Id())
 
-	return resourceElasticsearchScriptRead(d, m)
+	return resourceElasticsearchScriptRead(ctx, d, m)
 }
 
-func resourceElasticsearchScriptRead(d *schema.ResourceData, m interface{}) error {
-	scriptBody, err := resourceElasticsearchGetScript(d.Id(), m)
+func resourceElasticsearchScriptRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	scriptBody, err := resourceElasticsearchGetScript(ctx, d.Id(), m)
 
 	if elastic6.IsNotFound(err) || elastic7.IsNotFound(err) {
 		log.Printf("[WARN] Script (%s) not found, removing from state", d.Id())
@@ -100,7 +108,7 @@ func resourceElasticsearchScriptRead(d *schema.ResourceData, m interface{}) erro
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	ds := &resourceDataSetter{d: d}
@@ -108,38 +116,38 @@ func resourceElasticsearchScriptRead(d *schema.ResourceData, m interface{}) erro
 	ds.set("source", scriptBody.Source)
 	ds.set("lang", scriptBody.Language)
 
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func resourceElasticsearchScriptUpdate(d *schema.ResourceData, m interface{}) error {
-	_, err := resourceElasticsearchPutScript(d, m)
+func resourceElasticsearchScriptUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	_, err := resourceElasticsearchPutScript(ctx, d, m)
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceElasticsearchScriptRead(d, m)
+	return resourceElasticsearchScriptRead(ctx, d, m)
 }
 
-func resourceElasticsearchScriptDelete(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchScriptDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var err error
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.DeleteScript().Id(d.Id()).Do(context.TODO())
+		_, err = client.DeleteScript().Id(d.Id()).Do(ctx)
 	case *elastic6.Client:
-		_, err = client.DeleteScript().Id(d.Id()).Do(context.TODO())
+		_, err = client.DeleteScript().Id(d.Id()).Do(ctx)
 	default:
 		err = errors.New("script resource not implemented prior to Elastic v6")
 	}
 
-	return err
+	return diag.FromErr(err)
 }
 
-func resourceElasticsearchGetScript(scriptID string, m interface{}) (ScriptBody, error) {
+func resourceElasticsearchGetScript(ctx context.Context, scriptID string, m interface{}) (ScriptBody, error) {
 	var scriptBody json.RawMessage
 	var err error
 	esClient, err := getClient(m.(*ProviderConf))
@@ -149,14 +157,14 @@ func resourceElasticsearchGetScript(scriptID string, m interface{}) (ScriptBody,
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.GetScriptResponse
-		res, err = client.GetScript().Id(scriptID).Do(context.TODO())
+		res, err = client.GetScript().Id(scriptID).Do(ctx)
 		if err != nil {
 			return ScriptBody{}, err
 		}
 		scriptBody = res.Script
 	case *elastic6.Client:
 		var res *elastic6.GetScriptResponse
-		res, err = client.GetScript().Id(scriptID).Do(context.TODO())
+		res, err = client.GetScript().Id(scriptID).Do(ctx)
 		if err != nil {
 			return ScriptBody{}, err
 		}
@@ -174,7 +182,7 @@ func resourceElasticsearchGetScript(scriptID string, m interface{}) (ScriptBody,
 	return script, err
 }
 
-func resourceElasticsearchPutScript(d *schema.ResourceData, m interface{}) (string, error) {
+func resourceElasticsearchPutScript(ctx context.Context, d *schema.ResourceData, m interface{}) (string, error) {
 	var err error
 	scriptID := d.Get("script_id").(string)
 	scriptBody, err := buildScriptJSONBody(d)
@@ -192,12 +200,12 @@ func resourceElasticsearchPutScript(d *schema.ResourceData, m interface{}) (stri
 		_, err = client.PutScript().
 			Id(scriptID).
 			BodyJson(scriptBody).
-			Do(context.TODO())
+			Do(ctx)
 	case *elastic6.Client:
 		_, err = client.PutScript().
 			Id(scriptID).
 			BodyJson(scriptBody).
-			Do(context.TODO())
+			Do(ctx)
 	default:
 		err = errors.New("script resource not implemented prior to Elastic v6")
 	}
@@ -3,7 +3,9 @@ package es
 import (
 	"context"
 	"errors"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic6 "gopkg.in/olivere/elastic.v6"
@@ -11,10 +13,11 @@ import (
 
 func resourceElasticsearchSnapshotRepository() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchSnapshotRepositoryCreate,
-		Read:   resourceElasticsearchSnapshotRepositoryRead,
-		Update: resourceElasticsearchSnapshotRepositoryUpdate,
-		Delete: resourceElasticsearchSnapshotRepositoryDelete,
+		CreateContext: resourceElasticsearchSnapshotRepositoryCreate,
+		ReadContext:   resourceElasticsearchSnapshotRepositoryRead,
+		UpdateContext: resourceElasticsearchSnapshotRepositoryUpdate,
+		DeleteContext: resourceElasticsearchSnapshotRepositoryDelete,
+		CustomizeDiff: serverlessCustomizeDiff("elasticsearch_snapshot_repository"),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -33,19 +36,24 @@ func resourceElasticsearchSnapshotRepository() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchSnapshotRepositoryCreate(d *schema.ResourceData, meta interface{}) error {
-	err := resourceElasticsearchSnapshotRepositoryUpdate(d, meta)
-	if err != nil {
-		return err
+func resourceElasticsearchSnapshotRepositoryCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := resourceElasticsearchSnapshotRepositoryUpdate(ctx, d, meta); diags.HasError() {
+		return diags
 	}
 	d.SetId(d.Get("name").(string))
 	return nil
 }
 
-func resourceElasticsearchSnapshotRepositoryRead(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchSnapshotRepositoryRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
 	var repositoryType string
@@ -53,30 +61,30 @@ func resourceElasticsearchSnapshotRepositoryRead(d *schema.ResourceData, meta in
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		repositoryType, settings, err = elastic7SnapshotGetRepository(client, id)
+		repositoryType, settings, err = elastic7SnapshotGetRepository(ctx, client, id)
 	case *elastic6.Client:
-		repositoryType, settings, err = elastic6SnapshotGetRepository(client, id)
+		repositoryType, settings, err = elastic6SnapshotGetRepository(ctx, client, id)
 	default:
-		return errors.New("Elasticsearch version not supported")
+		return diag.FromErr(errors.New("Elasticsearch version not supported"))
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	ds := &resourceDataSetter{d: d}
 	ds.set("name", id)
 	ds.set("type", repositoryType)
 	ds.set("settings", settings)
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func elastic7SnapshotGetRepository(client *elastic7.Client, id string) (string, map[string]interface{}, error) {
-	repos, err := client.SnapshotGetRepository(id).Do(context.TODO())
+func elastic7SnapshotGetRepository(ctx context.Context, client *elastic7.Client, id string) (string, map[string]interface{}, error) {
+	repos, err := client.SnapshotGetRepository(id).Do(ctx)
 	if err != nil {
 		return "", make(map[string]interface{}), err
 	}
@@ -84,8 +92,8 @@ func elastic7SnapshotGetRepository(client *elastic7.Client, id string) (string,
 	return repos[id].Type, repos[id].Settings, nil
 }
 
-func elastic6SnapshotGetRepository(client *elastic6.Client, id string) (string, map[string]interface{}, error) {
-	repos, err := client.SnapshotGetRepository(id).Do(context.TODO())
+func elastic6SnapshotGetRepository(ctx context.Context, client *elastic6.Client, id string) (string, map[string]interface{}, error) {
+	repos, err := client.SnapshotGetRepository(id).Do(ctx)
 	if err != nil {
 		return "", make(map[string]interface{}), err
 	}
@@ -93,7 +101,7 @@ func elastic6SnapshotGetRepository(client *elastic6.Client, id string) (string,
 	return repos[id].Type, repos[id].Settings, nil
 }
 
-func resourceElasticsearchSnapshotRepositoryUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchSnapshotRepositoryUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	repositoryType := d.Get("type").(string)
 	name := d.Get("name").(string)
 
@@ -106,70 +114,70 @@ func resourceElasticsearchSnapshotRepositoryUpdate(d *schema.ResourceData, meta
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		err = elastic7SnapshotCreateRepository(client, name, repositoryType, settings)
+		err = elastic7SnapshotCreateRepository(ctx, client, name, repositoryType, settings)
 	case *elastic6.Client:
-		err = elastic6SnapshotCreateRepository(client, name, repositoryType, settings)
+		err = elastic6SnapshotCreateRepository(ctx, client, name, repositoryType, settings)
 	default:
-		return errors.New("Elasticsearch version not supported")
+		return diag.FromErr(errors.New("Elasticsearch version not supported"))
 	}
 
-	return err
+	return diag.FromErr(err)
 }
 
-func elastic7SnapshotCreateRepository(client *elastic7.Client, name string, repositoryType string, settings map[string]interface{}) error {
+func elastic7SnapshotCreateRepository(ctx context.Context, client *elastic7.Client, name string, repositoryType string, settings map[string]interface{}) error {
 	repo := elastic7.SnapshotRepositoryMetaData{
 		Type:     repositoryType,
 		Settings: settings,
 	}
 
-	_, err := client.SnapshotCreateRepository(name).BodyJson(&repo).Do(context.TODO())
+	_, err := client.SnapshotCreateRepository(name).BodyJson(&repo).Do(ctx)
 	return err
 }
 
-func elastic6SnapshotCreateRepository(client *elastic6.Client, name string, repositoryType string, settings map[string]interface{}) error {
+func elastic6SnapshotCreateRepository(ctx context.Context, client *elastic6.Client, name string, repositoryType string, settings map[string]interface{}) error {
 	repo := elastic6.SnapshotRepositoryMetaData{
 		Type:     repositoryType,
 		Settings: settings,
 	}
 
-	_, err := client.SnapshotCreateRepository(name).BodyJson(&repo).Do(context.TODO())
+	_, err := client.SnapshotCreateRepository(name).BodyJson(&repo).Do(ctx)
 	return err
 }
 
-func resourceElasticsearchSnapshotRepositoryDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchSnapshotRepositoryDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		err = elastic7SnapshotDeleteRepository(client, id)
+		err = elastic7SnapshotDeleteRepository(ctx, client, id)
 	case *elastic6.Client:
-		err = elastic6SnapshotDeleteRepository(client, id)
+		err = elastic6SnapshotDeleteRepository(ctx, client, id)
 	default:
-		return errors.New("Elasticsearch version not supported")
+		return diag.FromErr(errors.New("Elasticsearch version not supported"))
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId("")
 	return nil
 }
 
-func elastic7SnapshotDeleteRepository(client *elastic7.Client, id string) error {
-	_, err := client.SnapshotDeleteRepository(id).Do(context.TODO())
+func elastic7SnapshotDeleteRepository(ctx context.Context, client *elastic7.Client, id string) error {
+	_, err := client.SnapshotDeleteRepository(id).Do(ctx)
 	return err
 }
 
-func elastic6SnapshotDeleteRepository(client *elastic6.Client, id string) error {
-	_, err := client.SnapshotDeleteRepository(id).Do(context.TODO())
+func elastic6SnapshotDeleteRepository(ctx context.Context, client *elastic6.Client, id string) error {
+	_, err := client.SnapshotDeleteRepository(id).Do(ctx)
 	return err
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	elastic7 "github.com/olivere/elastic/v7"
@@ -27,55 +29,61 @@ var xPackIndexLifecyclePolicySchema = map[string]*schema.Schema{
 
 func resourceElasticsearchXpackIndexLifecyclePolicy() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchXpackIndexLifecyclePolicyCreate,
-		Read:   resourceElasticsearchXpackIndexLifecyclePolicyRead,
-		Update: resourceElasticsearchXpackIndexLifecyclePolicyUpdate,
-		Delete: resourceElasticsearchXpackIndexLifecyclePolicyDelete,
-		Schema: xPackIndexLifecyclePolicySchema,
+		CreateContext: resourceElasticsearchXpackIndexLifecyclePolicyCreate,
+		ReadContext:   resourceElasticsearchXpackIndexLifecyclePolicyRead,
+		UpdateContext: resourceElasticsearchXpackIndexLifecyclePolicyUpdate,
+		DeleteContext: resourceElasticsearchXpackIndexLifecyclePolicyDelete,
+		Schema:        xPackIndexLifecyclePolicySchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchXpackIndexLifecyclePolicyCreate(d *schema.ResourceData, meta interface{}) error {
-	err := resourceElasticsearchPutIndexLifecyclePolicy(d, meta)
+func resourceElasticsearchXpackIndexLifecyclePolicyCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	err := resourceElasticsearchPutIndexLifecyclePolicy(ctx, d, meta)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId(d.Get("name").(string))
 	return nil
 }
 
-func resourceElasticsearchXpackIndexLifecyclePolicyRead(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchXpackIndexLifecyclePolicyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
 	var result string
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		result, err = elastic7IndexGetLifecyclePolicy(client, id)
+		result, err = elastic7IndexGetLifecyclePolicy(ctx, client, id)
 	case *elastic6.Client:
-		result, err = elastic6IndexGetLifecyclePolicy(client, id)
+		result, err = elastic6IndexGetLifecyclePolicy(ctx, client, id)
 	default:
 		err = errors.New("Index Lifecycle Management is only supported by the elastic library >= v6!")
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	ds := &resourceDataSetter{d: d}
 	ds.set("name", d.Id())
 	ds.set("body", result)
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func elastic7IndexGetLifecyclePolicy(client *elastic7.Client, id string) (string, error) {
-	res, err := client.XPackIlmGetLifecycle().Policy(id).Do(context.TODO())
+func elastic7IndexGetLifecyclePolicy(ctx context.Context, client *elastic7.Client, id string) (string, error) {
+	res, err := client.XPackIlmGetLifecycle().Policy(id).Do(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -88,8 +96,8 @@ func elastic7IndexGetLifecyclePolicy(client *elastic7.Client, id string) (string
 	return string(tj), nil
 }
 
-func elastic6IndexGetLifecyclePolicy(client *elastic6.Client, id string) (string, error) {
-	res, err := client.XPackIlmGetLifecycle().Policy(id).Do(context.TODO())
+func elastic6IndexGetLifecyclePolicy(ctx context.Context, client *elastic6.Client, id string) (string, error) {
+	res, err := client.XPackIlmGetLifecycle().Policy(id).Do(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -102,45 +110,45 @@ func elastic6IndexGetLifecyclePolicy(client *elastic6.Client, id string) (string
 	return string(tj), nil
 }
 
-func resourceElasticsearchXpackIndexLifecyclePolicyUpdate(d *schema.ResourceData, meta interface{}) error {
-	return resourceElasticsearchPutIndexLifecyclePolicy(d, meta)
+func resourceElasticsearchXpackIndexLifecyclePolicyUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return diag.FromErr(resourceElasticsearchPutIndexLifecyclePolicy(ctx, d, meta))
 }
 
-func resourceElasticsearchXpackIndexLifecyclePolicyDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchXpackIndexLifecyclePolicyDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		err = elastic7IndexDeleteLifecyclePolicy(client, id)
+		err = elastic7IndexDeleteLifecyclePolicy(ctx, client, id)
 	case *elastic6.Client:
-		err = elastic6IndexDeleteLifecyclePolicy(client, id)
+		err = elastic6IndexDeleteLifecyclePolicy(ctx, client, id)
 	default:
 		err = errors.New("Index Lifecycle Management is only supported by the elastic library >= v6!")
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId("")
 	return nil
 }
 
-func elastic7IndexDeleteLifecyclePolicy(client *elastic7.Client, id string) error {
-	_, err := client.XPackIlmDeleteLifecycle().Policy(id).Do(context.TODO())
+func elastic7IndexDeleteLifecyclePolicy(ctx context.Context, client *elastic7.Client, id string) error {
+	_, err := client.XPackIlmDeleteLifecycle().Policy(id).Do(ctx)
 	return err
 }
 
-func elastic6IndexDeleteLifecyclePolicy(client *elastic6.Client, id string) error {
-	_, err := client.XPackIlmDeleteLifecycle().Policy(id).Do(context.TODO())
+func elastic6IndexDeleteLifecyclePolicy(ctx context.Context, client *elastic6.Client, id string) error {
+	_, err := client.XPackIlmDeleteLifecycle().Policy(id).Do(ctx)
 	return err
 }
 
-func resourceElasticsearchPutIndexLifecyclePolicy(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchPutIndexLifecyclePolicy(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	name := d.Get("name").(string)
 	body := d.Get("body").(string)
 
@@ -151,9 +159,9 @@ func resourceElasticsearchPutIndexLifecyclePolicy(d *schema.ResourceData, meta i
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		err = elastic7IndexPutLifecyclePolicy(client, name, body)
+		err = elastic7IndexPutLifecyclePolicy(ctx, client, name, body)
 	case *elastic6.Client:
-		err = elastic6IndexPutLifecyclePolicy(client, name, body)
+		err = elastic6IndexPutLifecyclePolicy(ctx, client, name, body)
 	default:
 		err = errors.New("resourceElasticsearchPutIndexLifecyclePolicy Index Lifecycle Management is only supported by the elastic library >= v6!")
 	}
@@ -161,12 +169,12 @@ func resourceElasticsearchPutIndexLifecyclePolicy(d *schema.ResourceData, meta i
 	return err
 }
 
-func elastic7IndexPutLifecyclePolicy(client *elastic7.Client, name string, body string) error {
-	_, err := client.XPackIlmPutLifecycle().Policy(name).BodyString(body).Do(context.TODO())
+func elastic7IndexPutLifecyclePolicy(ctx context.Context, client *elastic7.Client, name string, body string) error {
+	_, err := client.XPackIlmPutLifecycle().Policy(name).BodyString(body).Do(ctx)
 	return err
 }
 
-func elastic6IndexPutLifecyclePolicy(client *elastic6.Client, name string, body string) error {
-	_, err := client.XPackIlmPutLifecycle().Policy(name).BodyString(body).Do(context.TODO())
+func elastic6IndexPutLifecyclePolicy(ctx context.Context, client *elastic6.Client, name string, body string) error {
+	_, err := client.XPackIlmPutLifecycle().Policy(name).BodyString(body).Do(ctx)
 	return err
 }
@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -15,10 +17,10 @@ import (
 
 func resourceElasticsearchXpackLicense() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchLicenseCreate,
-		Read:   resourceElasticsearchLicenseRead,
-		Update: resourceElasticsearchLicenseUpdate,
-		Delete: resourceElasticsearchLicenseDelete,
+		CreateContext: resourceElasticsearchLicenseCreate,
+		ReadContext:   resourceElasticsearchLicenseRead,
+		UpdateContext: resourceElasticsearchLicenseUpdate,
+		DeleteContext: resourceElasticsearchLicenseDelete,
 		Schema: map[string]*schema.Schema{
 			"license": {
 				Type:             schema.TypeString,
@@ -37,32 +39,38 @@ func resourceElasticsearchXpackLicense() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchLicenseCreate(d *schema.ResourceData, meta interface{}) error {
-	id, err := resourceElasticsearchCreateXpackLicense(d, meta)
+func resourceElasticsearchLicenseCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	id, err := resourceElasticsearchCreateXpackLicense(ctx, d, meta)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(id)
-	return resourceElasticsearchLicenseRead(d, meta)
+	return resourceElasticsearchLicenseRead(ctx, d, meta)
 }
 
-func resourceElasticsearchLicenseUpdate(d *schema.ResourceData, meta interface{}) error {
-	_, err := resourceElasticsearchCreateXpackLicense(d, meta)
+func resourceElasticsearchLicenseUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	_, err := resourceElasticsearchCreateXpackLicense(ctx, d, meta)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceElasticsearchLicenseRead(d, meta)
+	return resourceElasticsearchLicenseRead(ctx, d, meta)
 }
 
-func resourceElasticsearchLicenseRead(d *schema.ResourceData, meta interface{}) error {
-	l, err := resourceElasticsearchGetXpackLicense(meta)
+func resourceElasticsearchLicenseRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	l, err := resourceElasticsearchGetXpackLicense(ctx, meta)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	ds := &resourceDataSetter{d: d}
@@ -71,42 +79,42 @@ func resourceElasticsearchLicenseRead(d *schema.ResourceData, meta interface{})
 
 	out, err := json.Marshal(l)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	ds.set("license_json", string(out))
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func resourceElasticsearchLicenseDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchLicenseDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	var err error
 
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "DELETE",
 			Path:   "/_license",
 		})
 	case *elastic6.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "DELETE",
 			Path:   "/_xpack/license",
 		})
 	default:
-		return errors.New("License is only supported by the elasticsearch >= v6!")
+		return diag.FromErr(errors.New("License is only supported by the elasticsearch >= v6!"))
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId("")
 	return nil
 }
 
-func resourceElasticsearchGetXpackLicense(meta interface{}) (License, error) {
+func resourceElasticsearchGetXpackLicense(ctx context.Context, meta interface{}) (License, error) {
 	license := new(License)
 
 	var body json.RawMessage
@@ -119,7 +127,7 @@ func resourceElasticsearchGetXpackLicense(meta interface{}) (License, error) {
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "GET",
 			Path:   "/_license",
 		})
@@ -129,7 +137,7 @@ func resourceElasticsearchGetXpackLicense(meta interface{}) (License, error) {
 		body = res.Body
 	case *elastic6.Client:
 		var res *elastic6.Response
-		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "GET",
 			Path:   "/_xpack/license",
 		})
@@ -150,16 +158,16 @@ func resourceElasticsearchGetXpackLicense(meta interface{}) (License, error) {
 	return licenseResponse["license"], err
 }
 
-func resourceElasticsearchCreateXpackLicense(d *schema.ResourceData, meta interface{}) (string, error) {
+func resourceElasticsearchCreateXpackLicense(ctx context.Context, d *schema.ResourceData, meta interface{}) (string, error) {
 	license := d.Get("license").(string)
 	useBasicLicense := d.Get("use_basic_license").(bool)
 
 	var l License
 	var err error
 	if !useBasicLicense {
-		l, err = resourceElasticsearchPutEnterpriseLicense(license, meta)
+		l, err = resourceElasticsearchPutEnterpriseLicense(ctx, license, meta)
 	} else if d.Id() == "" {
-		l, err = resourceElasticsearchPostBasicLicense(meta)
+		l, err = resourceElasticsearchPostBasicLicense(ctx, meta)
 	} else {
 		log.Printf("[INFO] skipping creating basic license because already enabled %s", d.Id())
 	}
@@ -171,7 +179,7 @@ func resourceElasticsearchCreateXpackLicense(d *schema.ResourceData, meta interf
 	return l.Uid, nil
 }
 
-func resourceElasticsearchPutEnterpriseLicense(l string, meta interface{}) (License, error) {
+func resourceElasticsearchPutEnterpriseLicense(ctx context.Context, l string, meta interface{}) (License, error) {
 	request := fmt.Sprintf(`{"licenses": [%s]}`, l)
 
 	var emptyLicense License
@@ -184,7 +192,7 @@ func resourceElasticsearchPutEnterpriseLicense(l string, meta interface{}) (Lice
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "PUT",
 			Path:   "/_license?acknowledge=true",
 			Body:   request,
@@ -195,7 +203,7 @@ func resourceElasticsearchPutEnterpriseLicense(l string, meta interface{}) (Lice
 		body = res.Body
 	case *elastic6.Client:
 		var res *elastic6.Response
-		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		res, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "PUT",
 			Path:   "/_xpack/license?acknowledge=true",
 			Body:   request,
@@ -230,7 +238,7 @@ func resourceElasticsearchPutEnterpriseLicense(l string, meta interface{}) (Lice
 	}
 }
 
-func resourceElasticsearchPostBasicLicense(meta interface{}) (License, error) {
+func resourceElasticsearchPostBasicLicense(ctx context.Context, meta interface{}) (License, error) {
 	var l License
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
@@ -239,12 +247,12 @@ func resourceElasticsearchPostBasicLicense(meta interface{}) (License, error) {
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 			Method: "POST",
 			Path:   "/_license/start_basic?acknowledge=true",
 		})
 	case *elastic6.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		_, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
 			Method: "POST",
 			Path:   "/_xpack/license/start_basic?acknowledge=true",
 		})
@@ -255,7 +263,7 @@ func resourceElasticsearchPostBasicLicense(meta interface{}) (License, error) {
 	if err != nil {
 		return l, err
 	}
-	return resourceElasticsearchGetXpackLicense(meta)
+	return resourceElasticsearchGetXpackLicense(ctx, meta)
 }
 
 type LicenseResponse struct {
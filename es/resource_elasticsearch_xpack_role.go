@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic6 "gopkg.in/olivere/elastic.v6"
@@ -14,10 +16,10 @@ import (
 
 func resourceElasticsearchXpackRole() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchXpackRoleCreate,
-		Read:   resourceElasticsearchXpackRoleRead,
-		Update: resourceElasticsearchXpackRoleUpdate,
-		Delete: resourceElasticsearchXpackRoleDelete,
+		CreateContext: resourceElasticsearchXpackRoleCreate,
+		ReadContext:   resourceElasticsearchXpackRoleRead,
+		UpdateContext: resourceElasticsearchXpackRoleUpdate,
+		DeleteContext: resourceElasticsearchXpackRoleDelete,
 
 		Schema: map[string]*schema.Schema{
 			"role_name": {
@@ -130,27 +132,33 @@ func resourceElasticsearchXpackRole() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchXpackRoleCreate(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchXpackRoleCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	name := d.Get("role_name").(string)
 
 	reqBody, err := buildPutRoleBody(d, m)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	err = xpackPutRole(d, m, name, reqBody)
+	err = xpackPutRole(ctx, d, m, name, reqBody)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId(name)
-	return resourceElasticsearchXpackRoleRead(d, m)
+	return resourceElasticsearchXpackRoleRead(ctx, d, m)
 }
 
-func resourceElasticsearchXpackRoleRead(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchXpackRoleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 
-	role, err := xpackGetRole(d, m, d.Id())
+	role, err := xpackGetRole(ctx, d, m, d.Id())
 
 	if err != nil {
 		log.Print("Error during read")
@@ -164,7 +172,7 @@ func resourceElasticsearchXpackRoleRead(d *schema.ResourceData, m interface{}) e
 			d.SetId("")
 			return nil
 		}
-		return err
+		return diag.FromErr(err)
 	}
 
 	ds := &resourceDataSetter{d: d}
@@ -202,26 +210,26 @@ func resourceElasticsearchXpackRoleRead(d *schema.ResourceData, m interface{}) e
 	ds.set("global", role.Global)
 	ds.set("run_as", role.RunAs)
 	ds.set("metadata", role.Metadata)
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func resourceElasticsearchXpackRoleUpdate(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchXpackRoleUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	name := d.Get("role_name").(string)
 
 	reqBody, err := buildPutRoleBody(d, m)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	err = xpackPutRole(d, m, name, reqBody)
+	err = xpackPutRole(ctx, d, m, name, reqBody)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	return resourceElasticsearchXpackRoleRead(d, m)
+	return resourceElasticsearchXpackRoleRead(ctx, d, m)
 }
 
-func resourceElasticsearchXpackRoleDelete(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchXpackRoleDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 
-	err := xpackDeleteRole(d, m, d.Id())
+	err := xpackDeleteRole(ctx, d, m, d.Id())
 	if err != nil {
 		log.Print("Error during destroy")
 		if elasticErr, ok := err.(*elastic7.Error); ok && elastic7.IsNotFound(elasticErr) {
@@ -290,65 +298,65 @@ func buildPutRoleBody(d *schema.ResourceData, m interface{}) (string, error) {
 	return string(body[:]), err
 }
 
-func xpackPutRole(d *schema.ResourceData, m interface{}, name string, body string) error {
+func xpackPutRole(ctx context.Context, d *schema.ResourceData, m interface{}, name string, body string) error {
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return err
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		return elastic7PutRole(client, name, body)
+		return elastic7PutRole(ctx, client, name, body)
 	case *elastic6.Client:
-		return elastic6PutRole(client, name, body)
+		return elastic6PutRole(ctx, client, name, body)
 	default:
 		return errors.New("unhandled client type")
 	}
 }
 
-func xpackGetRole(d *schema.ResourceData, m interface{}, name string) (XPackSecurityRole, error) {
+func xpackGetRole(ctx context.Context, d *schema.ResourceData, m interface{}, name string) (XPackSecurityRole, error) {
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return XPackSecurityRole{}, err
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		return elastic7GetRole(client, name)
+		return elastic7GetRole(ctx, client, name)
 	case *elastic6.Client:
-		return elastic6GetRole(client, name)
+		return elastic6GetRole(ctx, client, name)
 	default:
 		return XPackSecurityRole{}, errors.New("unhandled client type")
 	}
 }
 
-func xpackDeleteRole(d *schema.ResourceData, m interface{}, name string) error {
+func xpackDeleteRole(ctx context.Context, d *schema.ResourceData, m interface{}, name string) error {
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return err
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		return elastic7DeleteRole(client, name)
+		return elastic7DeleteRole(ctx, client, name)
 	case *elastic6.Client:
-		return elastic6DeleteRole(client, name)
+		return elastic6DeleteRole(ctx, client, name)
 	default:
 		return errors.New("unhandled client type")
 	}
 }
 
-func elastic6PutRole(client *elastic6.Client, name string, body string) error {
-	_, err := client.XPackSecurityPutRole(name).Body(body).Do(context.Background())
+func elastic6PutRole(ctx context.Context, client *elastic6.Client, name string, body string) error {
+	_, err := client.XPackSecurityPutRole(name).Body(body).Do(ctx)
 	log.Printf("[INFO] put error: %+v", err)
 	return err
 }
 
-func elastic7PutRole(client *elastic7.Client, name string, body string) error {
-	_, err := client.XPackSecurityPutRole(name).Body(body).Do(context.Background())
+func elastic7PutRole(ctx context.Context, client *elastic7.Client, name string, body string) error {
+	_, err := client.XPackSecurityPutRole(name).Body(body).Do(ctx)
 	log.Printf("[INFO] put error: %+v", err)
 	return err
 }
 
-func elastic6GetRole(client *elastic6.Client, name string) (XPackSecurityRole, error) {
-	res, err := client.XPackSecurityGetRole(name).Do(context.Background())
+func elastic6GetRole(ctx context.Context, client *elastic6.Client, name string) (XPackSecurityRole, error) {
+	res, err := client.XPackSecurityGetRole(name).Do(ctx)
 	if err != nil {
 		return XPackSecurityRole{}, err
 	}
@@ -391,8 +399,8 @@ func elastic6GetRole(client *elastic6.Client, name string) (XPackSecurityRole, e
 	return role, err
 }
 
-func elastic7GetRole(client *elastic7.Client, name string) (XPackSecurityRole, error) {
-	res, err := client.XPackSecurityGetRole(name).Do(context.Background())
+func elastic7GetRole(ctx context.Context, client *elastic7.Client, name string) (XPackSecurityRole, error) {
+	res, err := client.XPackSecurityGetRole(name).Do(ctx)
 	if err != nil {
 		return XPackSecurityRole{}, err
 	}
@@ -436,13 +444,13 @@ func elastic7GetRole(client *elastic7.Client, name string) (XPackSecurityRole, e
 	return role, err
 }
 
-func elastic6DeleteRole(client *elastic6.Client, name string) error {
-	_, err := client.XPackSecurityDeleteRole(name).Do(context.Background())
+func elastic6DeleteRole(ctx context.Context, client *elastic6.Client, name string) error {
+	_, err := client.XPackSecurityDeleteRole(name).Do(ctx)
 	return err
 }
 
-func elastic7DeleteRole(client *elastic7.Client, name string) error {
-	_, err := client.XPackSecurityDeleteRole(name).Do(context.Background())
+func elastic7DeleteRole(ctx context.Context, client *elastic7.Client, name string) error {
+	_, err := client.XPackSecurityDeleteRole(name).Do(ctx)
 	return err
 }
 
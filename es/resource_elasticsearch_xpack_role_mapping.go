@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic6 "gopkg.in/olivere/elastic.v6"
@@ -14,11 +16,11 @@ import (
 
 func resourceElasticsearchXpackRoleMapping() *schema.Resource {
 	return &schema.Resource{
-		Description: "Provides an Elasticsearch XPack role mapping resource. Role mappings define which roles are assigned to each user. Each mapping has rules that identify users and a list of roles that are granted to those users. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/security-api.html) for more details.",
-		Create:      resourceElasticsearchXpackRoleMappingCreate,
-		Read:        resourceElasticsearchXpackRoleMappingRead,
-		Update:      resourceElasticsearchXpackRoleMappingUpdate,
-		Delete:      resourceElasticsearchXpackRoleMappingDelete,
+		Description:   "Provides an Elasticsearch XPack role mapping resource. Role mappings define which roles are assigned to each user. Each mapping has rules that identify users and a list of roles that are granted to those users. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/security-api.html) for more details.",
+		CreateContext: resourceElasticsearchXpackRoleMappingCreate,
+		ReadContext:   resourceElasticsearchXpackRoleMappingRead,
+		UpdateContext: resourceElasticsearchXpackRoleMappingUpdate,
+		DeleteContext: resourceElasticsearchXpackRoleMappingDelete,
 
 		Schema: map[string]*schema.Schema{
 			"role_mapping_name": {
@@ -57,27 +59,33 @@ func resourceElasticsearchXpackRoleMapping() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchXpackRoleMappingCreate(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchXpackRoleMappingCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	name := d.Get("role_mapping_name").(string)
 
 	reqBody, err := buildPutRoleMappingBody(d, m)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	err = xpackPutRoleMapping(d, m, name, reqBody)
+	err = xpackPutRoleMapping(ctx, d, m, name, reqBody)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId(name)
-	return resourceElasticsearchXpackRoleMappingRead(d, m)
+	return resourceElasticsearchXpackRoleMappingRead(ctx, d, m)
 }
 
-func resourceElasticsearchXpackRoleMappingRead(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchXpackRoleMappingRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 
-	roleMapping, err := xpackGetRoleMapping(d, m, d.Id())
+	roleMapping, err := xpackGetRoleMapping(ctx, d, m, d.Id())
 	if err != nil {
 		fmt.Println("Error during read")
 		if elasticErr, ok := err.(*elastic7.Error); ok && elastic7.IsNotFound(elasticErr) {
@@ -90,7 +98,7 @@ func resourceElasticsearchXpackRoleMappingRead(d *schema.ResourceData, m interfa
 			d.SetId("")
 			return nil
 		}
-		return err
+		return diag.FromErr(err)
 	}
 
 	ds := &resourceDataSetter{d: d}
@@ -99,26 +107,26 @@ func resourceElasticsearchXpackRoleMappingRead(d *schema.ResourceData, m interfa
 	ds.set("enabled", roleMapping.Enabled)
 	ds.set("rules", roleMapping.Rules)
 	ds.set("metadata", roleMapping.Metadata)
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func resourceElasticsearchXpackRoleMappingUpdate(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchXpackRoleMappingUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	name := d.Get("role_mapping_name").(string)
 
 	reqBody, err := buildPutRoleMappingBody(d, m)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	err = xpackPutRoleMapping(d, m, name, reqBody)
+	err = xpackPutRoleMapping(ctx, d, m, name, reqBody)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	return resourceElasticsearchXpackRoleMappingRead(d, m)
+	return resourceElasticsearchXpackRoleMappingRead(ctx, d, m)
 }
 
-func resourceElasticsearchXpackRoleMappingDelete(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchXpackRoleMappingDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 
-	err := xpackDeleteRoleMapping(d, m, d.Id())
+	err := xpackDeleteRoleMapping(ctx, d, m, d.Id())
 	if err != nil {
 		fmt.Println("Error during destroy")
 		if elasticErr, ok := err.(*elastic7.Error); ok && elastic7.IsNotFound(elasticErr) {
@@ -156,65 +164,65 @@ func buildPutRoleMappingBody(d *schema.ResourceData, m interface{}) (string, err
 	return string(body[:]), err
 }
 
-func xpackPutRoleMapping(d *schema.ResourceData, m interface{}, name string, body string) error {
+func xpackPutRoleMapping(ctx context.Context, d *schema.ResourceData, m interface{}, name string, body string) error {
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return err
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		return elastic7PutRoleMapping(client, name, body)
+		return elastic7PutRoleMapping(ctx, client, name, body)
 	case *elastic6.Client:
-		return elastic6PutRoleMapping(client, name, body)
+		return elastic6PutRoleMapping(ctx, client, name, body)
 	default:
 		return errors.New("unhandled client type")
 	}
 }
 
-func xpackGetRoleMapping(d *schema.ResourceData, m interface{}, name string) (XPackSecurityRoleMapping, error) {
+func xpackGetRoleMapping(ctx context.Context, d *schema.ResourceData, m interface{}, name string) (XPackSecurityRoleMapping, error) {
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return XPackSecurityRoleMapping{}, err
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		return elastic7GetRoleMapping(client, name)
+		return elastic7GetRoleMapping(ctx, client, name)
 	case *elastic6.Client:
-		return elastic6GetRoleMapping(client, name)
+		return elastic6GetRoleMapping(ctx, client, name)
 	default:
 		return XPackSecurityRoleMapping{}, errors.New("unhandled client type")
 	}
 }
 
-func xpackDeleteRoleMapping(d *schema.ResourceData, m interface{}, name string) error {
+func xpackDeleteRoleMapping(ctx context.Context, d *schema.ResourceData, m interface{}, name string) error {
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return err
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		return elastic7DeleteRoleMapping(client, name)
+		return elastic7DeleteRoleMapping(ctx, client, name)
 	case *elastic6.Client:
-		return elastic6DeleteRoleMapping(client, name)
+		return elastic6DeleteRoleMapping(ctx, client, name)
 	default:
 		return errors.New("unhandled client type")
 	}
 }
 
-func elastic6PutRoleMapping(client *elastic6.Client, name string, body string) error {
-	resp, err := client.XPackSecurityPutRoleMapping(name).Body(body).Do(context.Background())
+func elastic6PutRoleMapping(ctx context.Context, client *elastic6.Client, name string, body string) error {
+	resp, err := client.XPackSecurityPutRoleMapping(name).Body(body).Do(ctx)
 	log.Printf("[INFO] put error: %+v, %+v", resp, err)
 	return err
 }
 
-func elastic7PutRoleMapping(client *elastic7.Client, name string, body string) error {
-	resp, err := client.XPackSecurityPutRoleMapping(name).Body(body).Do(context.Background())
+func elastic7PutRoleMapping(ctx context.Context, client *elastic7.Client, name string, body string) error {
+	resp, err := client.XPackSecurityPutRoleMapping(name).Body(body).Do(ctx)
 	log.Printf("[INFO] put error: %+v, %+v", resp, err)
 	return err
 }
 
-func elastic6GetRoleMapping(client *elastic6.Client, name string) (XPackSecurityRoleMapping, error) {
-	res, err := client.XPackSecurityGetRoleMapping(name).Do(context.Background())
+func elastic6GetRoleMapping(ctx context.Context, client *elastic6.Client, name string) (XPackSecurityRoleMapping, error) {
+	res, err := client.XPackSecurityGetRoleMapping(name).Do(ctx)
 	if err != nil {
 		return XPackSecurityRoleMapping{}, err
 	}
@@ -237,8 +245,8 @@ func elastic6GetRoleMapping(client *elastic6.Client, name string) (XPackSecurity
 	return roleMapping, err
 }
 
-func elastic7GetRoleMapping(client *elastic7.Client, name string) (XPackSecurityRoleMapping, error) {
-	res, err := client.XPackSecurityGetRoleMapping(name).Do(context.Background())
+func elastic7GetRoleMapping(ctx context.Context, client *elastic7.Client, name string) (XPackSecurityRoleMapping, error) {
+	res, err := client.XPackSecurityGetRoleMapping(name).Do(ctx)
 	if err != nil {
 		return XPackSecurityRoleMapping{}, err
 	}
@@ -261,13 +269,13 @@ func elastic7GetRoleMapping(client *elastic7.Client, name string) (XPackSecurity
 	return roleMapping, err
 }
 
-func elastic6DeleteRoleMapping(client *elastic6.Client, name string) error {
-	_, err := client.XPackSecurityDeleteRoleMapping(name).Do(context.Background())
+func elastic6DeleteRoleMapping(ctx context.Context, client *elastic6.Client, name string) error {
+	_, err := client.XPackSecurityDeleteRoleMapping(name).Do(ctx)
 	return err
 }
 
-func elastic7DeleteRoleMapping(client *elastic7.Client, name string) error {
-	_, err := client.XPackSecurityDeleteRoleMapping(name).Do(context.Background())
+func elastic7DeleteRoleMapping(ctx context.Context, client *elastic7.Client, name string) error {
+	_, err := client.XPackSecurityDeleteRoleMapping(name).Do(ctx)
 	return err
 }
 
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	elastic7 "github.com/olivere/elastic/v7"
@@ -13,11 +15,11 @@ import (
 
 func resourceElasticsearchXpackSnapshotLifecyclePolicy() *schema.Resource {
 	return &schema.Resource{
-		Description: "Provides an Elasticsearch XPack snapshot lifecycle management policy. These automatically take snapshots and control how long they are retained. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/snapshot-lifecycle-management-api.html) for more details.",
-		Create:      resourceElasticsearchXpackSnapshotLifecyclePolicyCreate,
-		Read:        resourceElasticsearchXpackSnapshotLifecyclePolicyRead,
-		Update:      resourceElasticsearchXpackSnapshotLifecyclePolicyUpdate,
-		Delete:      resourceElasticsearchXpackSnapshotLifecyclePolicyDelete,
+		Description:   "Provides an Elasticsearch XPack snapshot lifecycle management policy. These automatically take snapshots and control how long they are retained. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/snapshot-lifecycle-management-api.html) for more details.",
+		CreateContext: resourceElasticsearchXpackSnapshotLifecyclePolicyCreate,
+		ReadContext:   resourceElasticsearchXpackSnapshotLifecyclePolicyRead,
+		UpdateContext: resourceElasticsearchXpackSnapshotLifecyclePolicyUpdate,
+		DeleteContext: resourceElasticsearchXpackSnapshotLifecyclePolicyDelete,
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -36,45 +38,51 @@ func resourceElasticsearchXpackSnapshotLifecyclePolicy() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchXpackSnapshotLifecyclePolicyCreate(d *schema.ResourceData, meta interface{}) error {
-	err := resourceElasticsearchPutSnapshotLifecyclePolicy(d, meta)
+func resourceElasticsearchXpackSnapshotLifecyclePolicyCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	err := resourceElasticsearchPutSnapshotLifecyclePolicy(ctx, d, meta)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId(d.Get("name").(string))
 	return nil
 }
 
-func resourceElasticsearchXpackSnapshotLifecyclePolicyRead(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchXpackSnapshotLifecyclePolicyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
 	var result string
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		result, err = elastic7SnapshotGetLifecyclePolicy(client, id)
+		result, err = elastic7SnapshotGetLifecyclePolicy(ctx, client, id)
 	default:
 		err = errors.New("Snapshot Lifecycle Management is only supported by the elastic library >= v7!")
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	ds := &resourceDataSetter{d: d}
 	ds.set("name", d.Id())
 	ds.set("body", result)
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func elastic7SnapshotGetLifecyclePolicy(client *elastic7.Client, id string) (string, error) {
-	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+func elastic7SnapshotGetLifecyclePolicy(ctx context.Context, client *elastic7.Client, id string) (string, error) {
+	res, err := client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 		Method: http.MethodGet,
 		Path:   "/_slm/policy/" + id,
 	})
@@ -113,41 +121,41 @@ func elastic7SnapshotGetLifecyclePolicy(client *elastic7.Client, id string) (str
 	return string(tj), nil
 }
 
-func resourceElasticsearchXpackSnapshotLifecyclePolicyUpdate(d *schema.ResourceData, meta interface{}) error {
-	return resourceElasticsearchPutSnapshotLifecyclePolicy(d, meta)
+func resourceElasticsearchXpackSnapshotLifecyclePolicyUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return diag.FromErr(resourceElasticsearchPutSnapshotLifecyclePolicy(ctx, d, meta))
 }
 
-func resourceElasticsearchXpackSnapshotLifecyclePolicyDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchXpackSnapshotLifecyclePolicyDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
 
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		err = elastic7SnapshotDeleteLifecyclePolicy(client, id)
+		err = elastic7SnapshotDeleteLifecyclePolicy(ctx, client, id)
 	default:
 		err = errors.New("Snapshot Lifecycle Management is only supported by the elastic library >= v7!")
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId("")
 	return nil
 }
 
-func elastic7SnapshotDeleteLifecyclePolicy(client *elastic7.Client, id string) error {
-	_, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+func elastic7SnapshotDeleteLifecyclePolicy(ctx context.Context, client *elastic7.Client, id string) error {
+	_, err := client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 		Method: http.MethodDelete,
 		Path:   "/_slm/policy/" + id,
 	})
 	return err
 }
 
-func resourceElasticsearchPutSnapshotLifecyclePolicy(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchPutSnapshotLifecyclePolicy(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	name := d.Get("name").(string)
 	body := d.Get("body").(string)
 
@@ -158,7 +166,7 @@ func resourceElasticsearchPutSnapshotLifecyclePolicy(d *schema.ResourceData, met
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		err = elastic7SnapshotPutLifecyclePolicy(client, name, body)
+		err = elastic7SnapshotPutLifecyclePolicy(ctx, client, name, body)
 	default:
 		err = errors.New("resourceElasticsearchPutSnapshotLifecyclePolicy Snapshot Lifecycle Management is only supported by the elastic library >= v7!")
 	}
@@ -166,8 +174,8 @@ func resourceElasticsearchPutSnapshotLifecyclePolicy(d *schema.ResourceData, met
 	return err
 }
 
-func elastic7SnapshotPutLifecyclePolicy(client *elastic7.Client, name string, body string) error {
-	_, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+func elastic7SnapshotPutLifecyclePolicy(ctx context.Context, client *elastic7.Client, name string, body string) error {
+	_, err := client.PerformRequest(ctx, elastic7.PerformRequestOptions{
 		Method: http.MethodPut,
 		Path:   "/_slm/policy/" + name,
 		Body:   body,
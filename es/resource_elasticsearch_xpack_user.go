@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic6 "gopkg.in/olivere/elastic.v6"
@@ -14,11 +16,11 @@ import (
 
 func resourceElasticsearchXpackUser() *schema.Resource {
 	return &schema.Resource{
-		Description: "Provides an Elasticsearch XPack user resource. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/security-api.html) for more details.",
-		Create:      resourceElasticsearchXpackUserCreate,
-		Read:        resourceElasticsearchXpackUserRead,
-		Update:      resourceElasticsearchXpackUserUpdate,
-		Delete:      resourceElasticsearchXpackUserDelete,
+		Description:   "Provides an Elasticsearch XPack user resource. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/security-api.html) for more details.",
+		CreateContext: resourceElasticsearchXpackUserCreate,
+		ReadContext:   resourceElasticsearchXpackUserRead,
+		UpdateContext: resourceElasticsearchXpackUserUpdate,
+		DeleteContext: resourceElasticsearchXpackUserDelete,
 
 		Schema: map[string]*schema.Schema{
 			"username": {
@@ -81,27 +83,33 @@ func resourceElasticsearchXpackUser() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchXpackUserCreate(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchXpackUserCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	name := d.Get("username").(string)
 
 	reqBody, err := buildPutUserBody(d, m)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	err = xpackPutUser(d, m, name, reqBody)
+	err = xpackPutUser(ctx, d, m, name, reqBody)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId(name)
-	return resourceElasticsearchXpackUserRead(d, m)
+	return resourceElasticsearchXpackUserRead(ctx, d, m)
 }
 
-func resourceElasticsearchXpackUserRead(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchXpackUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 
-	user, err := xpackGetUser(d, m, d.Id())
+	user, err := xpackGetUser(ctx, d, m, d.Id())
 	if err != nil {
 		fmt.Println("Error during read")
 		if elasticErr, ok := err.(*elastic7.Error); ok && elastic7.IsNotFound(elasticErr) {
@@ -114,7 +122,7 @@ func resourceElasticsearchXpackUserRead(d *schema.ResourceData, m interface{}) e
 			d.SetId("")
 			return nil
 		}
-		return err
+		return diag.FromErr(err)
 	}
 
 	ds := &resourceDataSetter{d: d}
@@ -124,26 +132,26 @@ func resourceElasticsearchXpackUserRead(d *schema.ResourceData, m interface{}) e
 	ds.set("email", user.Email)
 	ds.set("metadata", user.Metadata)
 	ds.set("enabled", user.Enabled)
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func resourceElasticsearchXpackUserUpdate(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchXpackUserUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	name := d.Get("username").(string)
 
 	reqBody, err := buildPutUserBody(d, m)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	err = xpackPutUser(d, m, name, reqBody)
+	err = xpackPutUser(ctx, d, m, name, reqBody)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	return resourceElasticsearchXpackUserRead(d, m)
+	return resourceElasticsearchXpackUserRead(ctx, d, m)
 }
 
-func resourceElasticsearchXpackUserDelete(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchXpackUserDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 
-	err := xpackDeleteUser(d, m, d.Id())
+	err := xpackDeleteUser(ctx, d, m, d.Id())
 	if err != nil {
 		fmt.Println("Error during destroy")
 		if elasticErr, ok := err.(*elastic7.Error); ok && elastic7.IsNotFound(elasticErr) {
@@ -196,65 +204,65 @@ func buildPutUserBody(d *schema.ResourceData, m interface{}) (string, error) {
 	return string(body[:]), err
 }
 
-func xpackPutUser(d *schema.ResourceData, m interface{}, name string, body string) error {
+func xpackPutUser(ctx context.Context, d *schema.ResourceData, m interface{}, name string, body string) error {
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return err
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		return elastic7PutUser(client, name, body)
+		return elastic7PutUser(ctx, client, name, body)
 	case *elastic6.Client:
-		return elastic6PutUser(client, name, body)
+		return elastic6PutUser(ctx, client, name, body)
 	default:
 		return errors.New("unhandled client type")
 	}
 }
 
-func xpackGetUser(d *schema.ResourceData, m interface{}, name string) (XPackSecurityUser, error) {
+func xpackGetUser(ctx context.Context, d *schema.ResourceData, m interface{}, name string) (XPackSecurityUser, error) {
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return XPackSecurityUser{}, err
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		return elastic7GetUser(client, name)
+		return elastic7GetUser(ctx, client, name)
 	case *elastic6.Client:
-		return elastic6GetUser(client, name)
+		return elastic6GetUser(ctx, client, name)
 	default:
 		return XPackSecurityUser{}, errors.New("unhandled client type")
 	}
 }
 
-func xpackDeleteUser(d *schema.ResourceData, m interface{}, name string) error {
+func xpackDeleteUser(ctx context.Context, d *schema.ResourceData, m interface{}, name string) error {
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return err
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		return elastic7DeleteUser(client, name)
+		return elastic7DeleteUser(ctx, client, name)
 	case *elastic6.Client:
-		return elastic6DeleteUser(client, name)
+		return elastic6DeleteUser(ctx, client, name)
 	default:
 		return errors.New("unhandled client type")
 	}
 }
 
-func elastic6PutUser(client *elastic6.Client, name string, body string) error {
-	_, err := client.XPackSecurityPutUser(name).Body(body).Do(context.Background())
+func elastic6PutUser(ctx context.Context, client *elastic6.Client, name string, body string) error {
+	_, err := client.XPackSecurityPutUser(name).Body(body).Do(ctx)
 	log.Printf("[INFO] put error: %+v", err)
 	return err
 }
 
-func elastic7PutUser(client *elastic7.Client, name string, body string) error {
-	_, err := client.XPackSecurityPutUser(name).Body(body).Do(context.Background())
+func elastic7PutUser(ctx context.Context, client *elastic7.Client, name string, body string) error {
+	_, err := client.XPackSecurityPutUser(name).Body(body).Do(ctx)
 	log.Printf("[INFO] put error: %+v", err)
 	return err
 }
 
-func elastic6GetUser(client *elastic6.Client, name string) (XPackSecurityUser, error) {
-	res, err := client.XPackSecurityGetUser(name).Do(context.Background())
+func elastic6GetUser(ctx context.Context, client *elastic6.Client, name string) (XPackSecurityUser, error) {
+	res, err := client.XPackSecurityGetUser(name).Do(ctx)
 	if err != nil {
 		return XPackSecurityUser{}, err
 	}
@@ -273,8 +281,8 @@ func elastic6GetUser(client *elastic6.Client, name string) (XPackSecurityUser, e
 	return user, err
 }
 
-func elastic7GetUser(client *elastic7.Client, name string) (XPackSecurityUser, error) {
-	res, err := client.XPackSecurityGetUser(name).Do(context.Background())
+func elastic7GetUser(ctx context.Context, client *elastic7.Client, name string) (XPackSecurityUser, error) {
+	res, err := client.XPackSecurityGetUser(name).Do(ctx)
 	if err != nil {
 		return XPackSecurityUser{}, err
 	}
@@ -293,13 +301,13 @@ func elastic7GetUser(client *elastic7.Client, name string) (XPackSecurityUser, e
 	return user, err
 }
 
-func elastic6DeleteUser(client *elastic6.Client, name string) error {
-	_, err := client.XPackSecurityDeleteUser(name).Do(context.Background())
+func elastic6DeleteUser(ctx context.Context, client *elastic6.Client, name string) error {
+	_, err := client.XPackSecurityDeleteUser(name).Do(ctx)
 	return err
 }
 
-func elastic7DeleteUser(client *elastic7.Client, name string) error {
-	_, err := client.XPackSecurityDeleteUser(name).Do(context.Background())
+func elastic7DeleteUser(ctx context.Context, client *elastic7.Client, name string) error {
+	_, err := client.XPackSecurityDeleteUser(name).Do(ctx)
 	return err
 }
 
@@ -4,9 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"log"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -42,45 +43,51 @@ var xPackWatchSchema = map[string]*schema.Schema{
 
 func resourceElasticsearchXpackWatch() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchWatchCreate,
-		Read:   resourceElasticsearchWatchRead,
-		Update: resourceElasticsearchWatchUpdate,
-		Delete: resourceElasticsearchWatchDelete,
-		Schema: xPackWatchSchema,
+		CreateContext: resourceElasticsearchWatchCreate,
+		ReadContext:   resourceElasticsearchWatchRead,
+		UpdateContext: resourceElasticsearchWatchUpdate,
+		DeleteContext: resourceElasticsearchWatchDelete,
+		Schema:        xPackWatchSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 	}
 }
 
-func resourceElasticsearchWatchCreate(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchWatchCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	// Determine whether the watch already exists, otherwise the API will
 	// override an existing watch with the name.
 	watchID := d.Get("watch_id").(string)
-	_, err := resourceElasticsearchGetWatch(watchID, m)
+	_, err := resourceElasticsearchGetWatch(ctx, watchID, m)
 
 	if err == nil {
 		log.Printf("[INFO] watch exists: %+v", err)
-		return fmt.Errorf("watch already exists with ID: %v", watchID)
+		return diag.Errorf("watch already exists with ID: %v", watchID)
 	} else if err != nil && !elastic6.IsNotFound(err) && !elastic7.IsNotFound(err) {
-		return err
+		return diag.FromErr(err)
 	}
 
-	watchID, err = resourceElasticsearchPutWatch(d, m)
+	watchID, err = resourceElasticsearchPutWatch(ctx, d, m)
 
 	if err != nil {
 		log.Printf("[INFO] Failed to put watch: %+v", err)
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(watchID)
 	log.Printf("[INFO] Object ID: %s", d.Id())
 
-	return resourceElasticsearchWatchRead(d, m)
+	return resourceElasticsearchWatchRead(ctx, d, m)
 }
 
-func resourceElasticsearchWatchRead(d *schema.ResourceData, m interface{}) error {
-	res, err := resourceElasticsearchGetWatch(d.Id(), m)
+func resourceElasticsearchWatchRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	res, err := resourceElasticsearchGetWatch(ctx, d.Id(), m)
 
 	if elastic6.IsNotFound(err) || elastic7.IsNotFound(err) {
 		log.Printf("[WARN] Watch (%s) not found, removing from state", d.Id())
@@ -89,7 +96,7 @@ func resourceElasticsearchWatchRead(d *schema.ResourceData, m interface{}) error
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	var watch []byte
@@ -97,7 +104,7 @@ func resourceElasticsearchWatchRead(d *schema.ResourceData, m interface{}) error
 
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch esClient.(type) {
 	case *elastic7.Client:
@@ -111,7 +118,7 @@ func resourceElasticsearchWatchRead(d *schema.ResourceData, m interface{}) error
 	}
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	ds := &resourceDataSetter{d: d}
@@ -119,38 +126,38 @@ func resourceElasticsearchWatchRead(d *schema.ResourceData, m interface{}) error
 	ds.set("watch_id", d.Id())
 	ds.set("active", status)
 
-	return ds.err
+	return diag.FromErr(ds.err)
 }
 
-func resourceElasticsearchWatchUpdate(d *schema.ResourceData, m interface{}) error {
-	_, err := resourceElasticsearchPutWatch(d, m)
+func resourceElasticsearchWatchUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	_, err := resourceElasticsearchPutWatch(ctx, d, m)
 
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceElasticsearchWatchRead(d, m)
+	return resourceElasticsearchWatchRead(ctx, d, m)
 }
 
-func resourceElasticsearchWatchDelete(d *schema.ResourceData, m interface{}) error {
+func resourceElasticsearchWatchDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var err error
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.XPackWatchDelete(d.Id()).Do(context.TODO())
+		_, err = client.XPackWatchDelete(d.Id()).Do(ctx)
 	case *elastic6.Client:
-		_, err = client.XPackWatchDelete(d.Id()).Do(context.TODO())
+		_, err = client.XPackWatchDelete(d.Id()).Do(ctx)
 	default:
 		err = errors.New("watch resource not implemented prior to Elastic v6")
 	}
 
-	return err
+	return diag.FromErr(err)
 }
 
-func resourceElasticsearchGetWatch(watchID string, m interface{}) (interface{}, error) {
+func resourceElasticsearchGetWatch(ctx context.Context, watchID string, m interface{}) (interface{}, error) {
 	var res interface{}
 	var err error
 	esClient, err := getClient(m.(*ProviderConf))
@@ -159,9 +166,9 @@ func resourceElasticsearchGetWatch(watchID string, m interface{}) (interface{},
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		res, err = client.XPackWatchGet(watchID).Do(context.TODO())
+		res, err = client.XPackWatchGet(watchID).Do(ctx)
 	case *elastic6.Client:
-		res, err = client.XPackWatchGet(watchID).Do(context.TODO())
+		res, err = client.XPackWatchGet(watchID).Do(ctx)
 	default:
 		err = errors.New("watch resource not implemented prior to Elastic v6")
 	}
@@ -169,7 +176,7 @@ func resourceElasticsearchGetWatch(watchID string, m interface{}) (interface{},
 	return res, err
 }
 
-func resourceElasticsearchPutWatch(d *schema.ResourceData, m interface{}) (string, error) {
+func resourceElasticsearchPutWatch(ctx context.Context, d *schema.ResourceData, m interface{}) (string, error) {
 	watchID := d.Get("watch_id").(string)
 	watchJSON := d.Get("body").(string)
 	isActive := d.Get("active").(bool)
@@ -183,11 +190,11 @@ func resourceElasticsearchPutWatch(d *schema.ResourceData, m interface{}) (strin
 	case *elastic7.Client:
 		_, err = client.XPackWatchPut(watchID).
 			Body(watchJSON).
-			Do(context.TODO())
+			Do(ctx)
 	case *elastic6.Client:
 		_, err = client.XPackWatchPut(watchID).
 			Body(watchJSON).
-			Do(context.TODO())
+			Do(ctx)
 	default:
 		err = errors.New("watch resource not implemented prior to Elastic v6")
 	}
@@ -196,7 +203,7 @@ func resourceElasticsearchPutWatch(d *schema.ResourceData, m interface{}) (strin
 		return "", err
 	}
 
-	_, err = activateWatcher(esClient, watchID, isActive)
+	_, err = activateWatcher(ctx, esClient, watchID, isActive)
 
 	if err != nil {
 		return "", err
@@ -206,20 +213,20 @@ func resourceElasticsearchPutWatch(d *schema.ResourceData, m interface{}) (strin
 }
 
 // turn on or off the watcher
-func activateWatcher(esClient interface{}, watchID string, isActive bool) (string, error) {
+func activateWatcher(ctx context.Context, esClient interface{}, watchID string, isActive bool) (string, error) {
 	var err error
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		if isActive {
-			_, err = client.XPackWatchActivate(watchID).Do(context.TODO())
+			_, err = client.XPackWatchActivate(watchID).Do(ctx)
 		} else {
-			_, err = client.XPackWatchDeactivate(watchID).Do(context.TODO())
+			_, err = client.XPackWatchDeactivate(watchID).Do(ctx)
 		}
 	case *elastic6.Client:
 		if isActive {
-			_, err = client.XPackWatchActivate(watchID).Do(context.TODO())
+			_, err = client.XPackWatchActivate(watchID).Do(ctx)
 		} else {
-			_, err = client.XPackWatchDeactivate(watchID).Do(context.TODO())
+			_, err = client.XPackWatchDeactivate(watchID).Do(ctx)
 		}
 	default:
 		err = errors.New("watch resource not implemented prior to Elastic v6")
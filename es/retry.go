@@ -0,0 +1,194 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// retrier retries failed requests with an exponential backoff between
+// minBackoff and maxBackoff, up to maxRetries times. It satisfies both the v6
+// and v7 elastic client Retrier interfaces.
+type retrier struct {
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+func newRetrier(conf *ProviderConf) *retrier {
+	if conf.maxRetries <= 0 {
+		return nil
+	}
+	return &retrier{
+		maxRetries: conf.maxRetries,
+		minBackoff: time.Duration(conf.retryBackoffMinMs) * time.Millisecond,
+		maxBackoff: time.Duration(conf.retryBackoffMaxMs) * time.Millisecond,
+	}
+}
+
+func (r *retrier) Retry(ctx context.Context, retry int, req *http.Request, resp *http.Response, err error) (time.Duration, bool, error) {
+	if retry > r.maxRetries || ctx.Err() != nil {
+		return 0, false, nil
+	}
+	var readOnlyErr *readOnlyError
+	if errors.As(err, &readOnlyErr) {
+		return 0, false, nil
+	}
+	if !retryable(req, resp, err) {
+		return 0, false, nil
+	}
+
+	wait := r.backoff(retry)
+	if resp != nil {
+		// honor the server asking us to slow down, e.g. on a 429
+		if s, perr := strconv.Atoi(resp.Header.Get("Retry-After")); perr == nil {
+			if after := time.Duration(s) * time.Second; after > wait {
+				wait = after
+			}
+		}
+		if wait > r.maxBackoff {
+			wait = r.maxBackoff
+		}
+	}
+
+	request := "request"
+	if req != nil {
+		request = req.Method + " " + req.URL.Path
+	}
+	reason := "no connection available"
+	if resp != nil {
+		reason = resp.Status
+	} else if err != nil {
+		reason = err.Error()
+	}
+	log.Printf("[WARN] Retrying %s in %s (%d/%d): %s", request, wait, retry, r.maxRetries, reason)
+
+	// the clients don't close the body of responses they retry, which would
+	// leak the connection and the slot of `max_concurrent_requests` it holds
+	if resp != nil && resp.Body != nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	return wait, true, nil
+}
+
+// statusRetryTransport retries responses with one of statusCodes, for the v6
+// client which only retries connection errors. Each attempt gets its own
+// request timeout, as with the retries of the v7 client.
+type statusRetryTransport struct {
+	rt          http.RoundTripper
+	retrier     *retrier
+	statusCodes []int
+	timeout     time.Duration
+}
+
+func (t statusRetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+
+	for retry := 1; ; retry++ {
+		var (
+			ctx    context.Context
+			cancel context.CancelFunc
+		)
+		if t.timeout > 0 {
+			ctx, cancel = context.WithTimeout(req.Context(), t.timeout)
+		} else {
+			ctx, cancel = context.WithCancel(req.Context())
+		}
+		attempt := req.WithContext(ctx)
+		if body != nil {
+			attempt.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		res, err := t.rt.RoundTrip(attempt)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		if t.retryStatus(res.StatusCode) {
+			wait, ok, _ := t.retrier.Retry(req.Context(), retry, attempt, res, nil)
+			if ok {
+				cancel()
+				select {
+				case <-time.After(wait):
+					continue
+				case <-req.Context().Done():
+					return nil, req.Context().Err()
+				}
+			}
+		}
+		// the attempt is cancelled once its response was read
+		res.Body = &releaseOnClose{ReadCloser: res.Body, release: cancel}
+		return res, nil
+	}
+}
+
+func (t statusRetryTransport) retryStatus(code int) bool {
+	for _, c := range t.statusCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// retryable returns whether a failed request may be sent again. Idempotent
+// requests are always retried. Other requests, e.g. POST creating an object
+// with a generated ID, are only retried if they weren't sent, or were
+// rejected with a 429 or 503 before being applied, so a proxy timing out
+// after the cluster applied them doesn't create duplicates.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req == nil {
+		// no connection was available, nothing was sent
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	if resp != nil {
+		return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// backoff returns the wait before the given retry, doubling from minBackoff
+// and capped at maxBackoff, with jitter so concurrent requests don't retry in
+// lockstep.
+func (r *retrier) backoff(retry int) time.Duration {
+	wait := r.minBackoff
+	for i := 1; i < retry && wait < r.maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > r.maxBackoff {
+		wait = r.maxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
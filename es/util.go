@@ -26,11 +26,11 @@ var (
 	errObjNotFound = fmt.Errorf("object not found")
 )
 
-func elastic7GetObject(client *elastic7.Client, index string, id string) (*elastic7.GetResult, error) {
+func elastic7GetObject(ctx context.Context, client *elastic7.Client, index string, id string) (*elastic7.GetResult, error) {
 	result, err := client.Get().
 		Index(index).
 		Id(id).
-		Do(context.TODO())
+		Do(ctx)
 
 	if err != nil {
 		return nil, err
@@ -42,12 +42,12 @@ func elastic7GetObject(client *elastic7.Client, index string, id string) (*elast
 	return result, nil
 }
 
-func elastic6GetObject(client *elastic6.Client, objectType string, index string, id string) (*elastic6.GetResult, error) {
+func elastic6GetObject(ctx context.Context, client *elastic6.Client, objectType string, index string, id string) (*elastic6.GetResult, error) {
 	result, err := client.Get().
 		Index(index).
 		Type(objectType).
 		Id(id).
-		Do(context.TODO())
+		Do(ctx)
 
 	if err != nil {
 		return nil, err
@@ -200,6 +200,32 @@ func normalizedIndexSettings(settings map[string]interface{}) map[string]interfa
 	return f
 }
 
+// normalizeIndexAliases normalizes the aliases of an index as returned by the
+// get alias API, which splits `routing` into index and search routing and
+// returns routing values as strings.
+func normalizeIndexAliases(aliases map[string]interface{}) {
+	for name, alias := range aliases {
+		aliasMap, ok := alias.(map[string]interface{})
+		if !ok {
+			aliasMap = map[string]interface{}{}
+			aliases[name] = aliasMap
+		}
+		if routing, ok := aliasMap["routing"]; ok {
+			for _, k := range []string{"index_routing", "search_routing"} {
+				if _, ok := aliasMap[k]; !ok {
+					aliasMap[k] = routing
+				}
+			}
+			delete(aliasMap, "routing")
+		}
+		for _, k := range []string{"index_routing", "search_routing"} {
+			if routing, ok := aliasMap[k]; ok {
+				aliasMap[k] = fmt.Sprintf("%v", routing)
+			}
+		}
+	}
+}
+
 func normalizeIndexLifecyclePolicy(pol map[string]interface{}) {
 	delete(pol, "version")
 	delete(pol, "modified_date")
@@ -250,6 +276,27 @@ func flattenMap(m map[string]interface{}) map[string]interface{} {
 	return f
 }
 
+// unflattenMap is the inverse of flattenMap, it nests the values of keys
+// containing dots.
+func unflattenMap(f map[string]interface{}) map[string]interface{} {
+	m := make(map[string]interface{})
+	for k, v := range f {
+		parts := strings.Split(k, ".")
+		current := m
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := current[part].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				current[part] = next
+			}
+			current = next
+		}
+		current[parts[len(parts)-1]] = v
+	}
+
+	return m
+}
+
 func concatStringSlice(args ...[]string) []string {
 	merged := make([]string, 0)
 	for _, slice := range args {
@@ -0,0 +1,123 @@
+package es
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+type warningsKey struct{}
+
+// warningCollector gathers the `Warning` response headers of the requests
+// made for a resource, e.g. deprecation warnings for legacy templates.
+type warningCollector struct {
+	mu       sync.Mutex
+	seen     map[string]bool
+	warnings []string
+}
+
+func (c *warningCollector) add(warning string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// retried and repeated requests return the same warnings
+	if c.seen[warning] {
+		return
+	}
+	if c.seen == nil {
+		c.seen = map[string]bool{}
+	}
+	c.seen[warning] = true
+	c.warnings = append(c.warnings, warning)
+}
+
+func (c *warningCollector) diagnostics() diag.Diagnostics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var diags diag.Diagnostics
+	for _, warning := range c.warnings {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "The cluster returned a warning",
+			Detail:   warning,
+		})
+	}
+	return diags
+}
+
+// warningTransport records the `Warning` headers of responses in the
+// collector of the request context, if any.
+type warningTransport struct {
+	rt http.RoundTripper
+}
+
+func (t warningTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.rt.RoundTrip(req)
+	if res == nil {
+		return res, err
+	}
+
+	if c, ok := req.Context().Value(warningsKey{}).(*warningCollector); ok {
+		for _, value := range res.Header.Values("Warning") {
+			c.add(parseWarningHeader(value))
+		}
+	}
+	return res, err
+}
+
+// parseWarningHeader returns the text of a `Warning` header, formatted as
+// `299 Elasticsearch-7.17.0-abc "text" "date"`, or the header as is if it
+// doesn't follow that format.
+func parseWarningHeader(value string) string {
+	start := strings.IndexByte(value, '"')
+	if start < 0 {
+		return value
+	}
+
+	var text strings.Builder
+	for i := start + 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if i+1 < len(value) {
+				i++
+				text.WriteByte(value[i])
+			}
+		case '"':
+			return text.String()
+		default:
+			text.WriteByte(value[i])
+		}
+	}
+	return value
+}
+
+// withWarnings wraps the CRUD functions of a resource so the `Warning` headers
+// returned by the cluster for the requests they make are added to their
+// diagnostics, surfacing deprecations before an upgrade removes them.
+func withWarnings(r *schema.Resource) {
+	wrap := func(fn func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			c := &warningCollector{}
+			diags := fn(context.WithValue(ctx, warningsKey{}, c), d, meta)
+			return append(diags, c.diagnostics()...)
+		}
+	}
+
+	if fn := r.CreateContext; fn != nil {
+		r.CreateContext = wrap(fn)
+	}
+	if fn := r.ReadContext; fn != nil {
+		r.ReadContext = wrap(fn)
+	}
+	if fn := r.UpdateContext; fn != nil {
+		r.UpdateContext = wrap(fn)
+	}
+	if fn := r.DeleteContext; fn != nil {
+		r.DeleteContext = wrap(fn)
+	}
+}
@@ -0,0 +1,5 @@
+data "elasticsearch_current_identity" "current" {}
+
+output "elasticsearch_user" {
+  value = data.elasticsearch_current_identity.current.username
+}
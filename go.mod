@@ -4,7 +4,6 @@ go 1.18
 
 require (
 	github.com/aws/aws-sdk-go v1.43.21
-	github.com/deoxxa/aws_signing_client v0.0.0-20161109131055-c20ee106809e
 	github.com/hashicorp/go-hclog v1.2.0
 	github.com/hashicorp/go-version v1.4.0
 	github.com/hashicorp/terraform-plugin-docs v0.4.0